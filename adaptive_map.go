@@ -0,0 +1,268 @@
+package gomap
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/exp/constraints"
+)
+
+const (
+	// adaptiveWindow is the default number of operations between two evaluations of the backend
+	adaptiveWindow = 4096
+	// adaptiveSmallLen is the length under which the sorted slice is kept, as the most compact backend
+	adaptiveSmallLen = 256
+	// adaptiveMoveCost is the cost of moving an item of the sorted slice on insert or delete,
+	// relative to comparing two keys
+	adaptiveMoveCost = 1.0 / 16
+	// adaptiveHashCost is the cost of a hash map operation, relative to comparing two keys
+	adaptiveHashCost = 4
+	// adaptiveHysteresis is how much cheaper the other backend must be to migrate to it
+	adaptiveHysteresis = 2
+	// adaptiveHistory is the number of migrations kept
+	adaptiveHistory = 64
+)
+
+// AdaptiveMap is a Map which migrates its entries between a sorted slice and a hash map,
+// following its length and how it is used
+type AdaptiveMap[K constraints.Ordered, V any] interface {
+	Map[K, V]
+	// Range iterates in key order whatever the backend, see Iterable
+	Iterable[K, V]
+	// Backend returns the backend holding the entries, BackendSortedSliceMap or BackendPureMap
+	Backend() BackendType
+	// Migrations returns the last migrations, the oldest first
+	Migrations() []Migration
+}
+
+// Migration is a change of the backend of an AdaptiveMap,
+// with the operations counted in the window which triggered it
+type Migration struct {
+	From, To BackendType
+	At       time.Time
+	Len      int
+	Reads    int64
+	Writes   int64
+	Ordered  int64
+}
+
+// WithAdaptiveWindow sets the number of operations between two evaluations of the backend of an AdaptiveMap
+func WithAdaptiveWindow(n int) Option {
+	return func(o *option) {
+		o.adaptiveWindow = n
+	}
+}
+
+type adaptiveMap[K constraints.Ordered, V any] struct {
+	mu         sync.RWMutex
+	backend    Map[K, V] // *sortedSliceMap or *pureMap
+	migrations []Migration
+	clock      Clock
+	window     int64
+
+	// the operations of the current window
+	ops, reads, writes, ordered atomic.Int64
+}
+
+// NewAdaptiveMap creates a map starting as a sorted slice.
+// Every window of operations it estimates the cost of the reads, writes and ordered Range of the window
+// on a sorted slice and on a hash map, and migrates to the hash map when it would be cheaper,
+// typically for large maps with frequent writes, or back to the sorted slice.
+// Maps of less than 256 entries stay sorted slices.
+// These are the only two backends: it neither bounds its entries nor filters its reads.
+// Clear starts over from an empty sorted slice.
+// thread-safe
+func NewAdaptiveMap[K constraints.Ordered, V any](opts ...Option) AdaptiveMap[K, V] {
+	opt := option{
+		clock:          systemClock{},
+		adaptiveWindow: adaptiveWindow,
+	}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	return &adaptiveMap[K, V]{
+		backend: NewSortedSliceMap[K, V](),
+		clock:   opt.clock,
+		window:  int64(max(opt.adaptiveWindow, 1)),
+	}
+}
+
+func (m *adaptiveMap[K, V]) Store(key K, val V) {
+	m.mu.Lock()
+	m.backend.Store(key, val)
+	m.mu.Unlock()
+	m.count(&m.writes)
+}
+
+func (m *adaptiveMap[K, V]) Load(key K) (V, bool) {
+	m.mu.RLock()
+	val, ok := m.backend.Load(key)
+	m.mu.RUnlock()
+	m.count(&m.reads)
+	return val, ok
+}
+
+func (m *adaptiveMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	m.mu.Lock()
+	val, ok := m.backend.LoadAndDelete(key)
+	m.mu.Unlock()
+	m.count(&m.writes)
+	return val, ok
+}
+
+func (m *adaptiveMap[K, V]) Delete(key K) {
+	m.mu.Lock()
+	m.backend.Delete(key)
+	m.mu.Unlock()
+	m.count(&m.writes)
+}
+
+func (m *adaptiveMap[K, V]) Contain(key K) bool {
+	m.mu.RLock()
+	ok := m.backend.Contain(key)
+	m.mu.RUnlock()
+	m.count(&m.reads)
+	return ok
+}
+
+// Clear resets the backend to a sorted slice, and starts a new window
+func (m *adaptiveMap[K, V]) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.backend = NewSortedSliceMap[K, V]()
+	m.ops.Store(0)
+	m.reads.Store(0)
+	m.writes.Store(0)
+	m.ordered.Store(0)
+}
+
+func (m *adaptiveMap[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.backend.(Iterable[K, V]).Len()
+}
+
+// Range iterates in key order, a hash map sorts its entries first
+func (m *adaptiveMap[K, V]) Range(f func(key K, val V) bool) {
+	defer m.count(&m.ordered)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []sliceItem[K, V]
+	switch b := m.backend.(type) {
+	case *sortedSliceMap[K, V]:
+		items = b.store
+	case *pureMap[K, V]:
+		items = sortedItems(b.store)
+	}
+	for _, item := range items {
+		if !f(item.k, item.v) {
+			return
+		}
+	}
+}
+
+func (m *adaptiveMap[K, V]) Backend() BackendType {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.backend.(typedBackend).backendType()
+}
+
+func (m *adaptiveMap[K, V]) describe() Description {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	inner := m.backend.(describer).describe()
+	c := inner.Complexity
+	if _, ok := m.backend.(*pureMap[K, V]); ok {
+		c.Range = sortTime
+	}
+	return Description{Backend: "AdaptiveMap", Capabilities: CapOrdered | CapThreadSafe, Complexity: c, Inner: &inner}
+}
+
+func (m *adaptiveMap[K, V]) Migrations() []Migration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.migrations)
+}
+
+// count counts an operation, and evaluates the backend at the end of a window
+func (m *adaptiveMap[K, V]) count(c *atomic.Int64) {
+	c.Add(1)
+	if m.ops.Add(1)%m.window == 0 {
+		m.adapt()
+	}
+}
+
+// adapt migrates to the backend which would have run the last window the cheapest
+func (m *adaptiveMap[K, V]) adapt() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reads, writes, ordered := m.reads.Swap(0), m.writes.Swap(0), m.ordered.Swap(0)
+	n := m.backend.(Iterable[K, V]).Len()
+
+	from := m.backend.(typedBackend).backendType()
+	var to BackendType
+	switch b := m.backend.(type) {
+	case *sortedSliceMap[K, V]:
+		if n < adaptiveSmallLen || sortedCost(n, reads, writes, ordered) <= adaptiveHysteresis*hashCost(n, reads, writes, ordered) {
+			return
+		}
+		to = BackendPureMap
+		hash := NewPureMap[K, V]()
+		for _, item := range b.store {
+			hash.store[item.k] = item.v
+		}
+		m.backend = hash
+	case *pureMap[K, V]:
+		if n >= adaptiveSmallLen/2 && hashCost(n, reads, writes, ordered) <= adaptiveHysteresis*sortedCost(n, reads, writes, ordered) {
+			return
+		}
+		to = BackendSortedSliceMap
+		m.backend = &sortedSliceMap[K, V]{store: sortedItems(b.store)}
+	}
+
+	if len(m.migrations) == adaptiveHistory {
+		m.migrations = slices.Delete(m.migrations, 0, 1)
+	}
+	m.migrations = append(m.migrations, Migration{
+		From:    from,
+		To:      to,
+		At:      m.clock.Now(),
+		Len:     n,
+		Reads:   reads,
+		Writes:  writes,
+		Ordered: ordered,
+	})
+}
+
+// sortedCost estimates the cost of a window on a sorted slice of n entries:
+// binary searches, moving half of the items on a write, and an ordered iteration being a scan
+func sortedCost(n int, reads, writes, ordered int64) float64 {
+	search := math.Log2(float64(n) + 1)
+	return float64(reads)*search + float64(writes)*(search+float64(n)/2*adaptiveMoveCost) + float64(ordered)*float64(n)
+}
+
+// hashCost estimates the cost of a window on a hash map of n entries,
+// an ordered iteration sorting the entries
+func hashCost(n int, reads, writes, ordered int64) float64 {
+	return float64(reads+writes)*adaptiveHashCost + float64(ordered)*float64(n)*(math.Log2(float64(n)+1)+1)
+}
+
+// sortedItems returns the entries of store sorted by key
+func sortedItems[K constraints.Ordered, V any](store map[K]V) []sliceItem[K, V] {
+	items := make([]sliceItem[K, V], 0, len(store))
+	for k, v := range store {
+		items = append(items, sliceItem[K, V]{k, v})
+	}
+	slices.SortFunc(items, func(a, b sliceItem[K, V]) int {
+		return cmp.Compare(a.k, b.k)
+	})
+	return items
+}
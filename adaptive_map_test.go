@@ -0,0 +1,151 @@
+package gomap
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveMap(t *testing.T) {
+	clock := newFakeClock()
+	m := NewAdaptiveMap[int, int](WithAdaptiveWindow(1000), WithClock(clock))
+	expectBackend := func(want BackendType, migrations int) {
+		t.Helper()
+		if got := m.Backend(); got != want {
+			t.Fatalf("Expected backend %v, but got %v", want, got)
+		}
+		if got := len(m.Migrations()); got != migrations {
+			t.Fatalf("Expected %d migrations, but got %d", migrations, got)
+		}
+	}
+
+	// A small map stays a sorted slice, even written only
+	for i := 0; i < 1000; i++ {
+		m.Store(i%100, i)
+	}
+	expectBackend(BackendSortedSliceMap, 0)
+
+	// Inserting in a large map moves too many items
+	clock.Advance(time.Minute)
+	r := rand.New(rand.NewSource(1))
+	for _, key := range r.Perm(10_000) {
+		m.Store(key, key)
+	}
+	expectBackend(BackendPureMap, 1)
+	got := m.Migrations()[0]
+	want := Migration{From: BackendSortedSliceMap, To: BackendPureMap, At: time.Unix(60, 0), Len: got.Len, Writes: 1000}
+	if got != want || got.Len < adaptiveSmallLen {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+	if m.Len() != 10_000 {
+		t.Errorf("Expected 10000, but got %d", m.Len())
+	}
+
+	// Range is ordered on a hash map too, and scanning a sorted slice is cheaper
+	for i := 0; i < 1000; i++ {
+		if i%100 == 0 {
+			prev := -1
+			m.Range(func(key, val int) bool {
+				if key <= prev || key != val {
+					t.Fatalf("Expected an ordered Range, but got %d after %d", key, prev)
+				}
+				prev = key
+				return true
+			})
+		} else if val, ok := m.Load(i); !ok || val != i {
+			t.Fatalf("Expected %d, but got %d, %v", i, val, ok)
+		}
+	}
+	expectBackend(BackendSortedSliceMap, 2)
+
+	// Written again, then shrinking back to a small map
+	for i := 0; i < 2000; i++ {
+		m.Store(r.Intn(10_000), i)
+	}
+	expectBackend(BackendPureMap, 3)
+	for key := 0; key < 10_000-50; key++ {
+		m.Delete(key)
+	}
+	for i := 0; i < 2000; i++ {
+		m.Contain(i)
+	}
+	expectBackend(BackendSortedSliceMap, 4)
+	if m.Len() != 50 {
+		t.Errorf("Expected 50, but got %d", m.Len())
+	}
+	for key := 10_000 - 50; key < 10_000; key++ {
+		if !m.Contain(key) {
+			t.Errorf("Expected %d to be kept by the migrations", key)
+		}
+	}
+}
+
+func TestAdaptiveMap_Clear(t *testing.T) {
+	m := NewAdaptiveMap[int, int](WithAdaptiveWindow(1000)).(*adaptiveMap[int, int])
+	for i := 0; i < 10_000; i++ {
+		m.Store(i*7919%10_000, i)
+	}
+	if got := m.Backend(); got != BackendPureMap {
+		t.Fatalf("Expected backend %v, but got %v", BackendPureMap, got)
+	}
+
+	// An empty map starts over as a sorted slice, with a new window
+	m.Store(1, 1)
+	m.Clear()
+	if got := m.Backend(); got != BackendSortedSliceMap || m.Len() != 0 {
+		t.Errorf("Clear: Expected an empty %v, but got %d entries in %v", BackendSortedSliceMap, m.Len(), got)
+	}
+	if m.ops.Load() != 0 || m.writes.Load() != 0 {
+		t.Errorf("Clear: Expected a new window, but got %d operations", m.ops.Load())
+	}
+	m.Store(2, 2)
+	if val, ok := m.Load(2); !ok || val != 2 || m.Contain(1) {
+		t.Errorf("Clear: Expected the map to be usable, but got %d", val)
+	}
+}
+
+func TestAdaptiveMap_History(t *testing.T) {
+	m := NewAdaptiveMap[int, int](WithAdaptiveWindow(1)).(*adaptiveMap[int, int])
+	for i := 0; i < adaptiveSmallLen; i++ {
+		m.Store(i, i)
+	}
+	// Every write migrates to the hash map, then every Range back to the sorted slice
+	for i := 0; i < adaptiveHistory; i++ {
+		m.Store(-1, i)
+		m.Range(func(key, val int) bool { return true })
+	}
+	migrations := m.Migrations()
+	if len(migrations) != adaptiveHistory {
+		t.Fatalf("Expected %d migrations, but got %d", adaptiveHistory, len(migrations))
+	}
+	if last := migrations[len(migrations)-1]; last.To != BackendSortedSliceMap || last.Ordered != 1 {
+		t.Errorf("Expected the last migration to the sorted slice on a Range, but got %+v", last)
+	}
+}
+
+func TestAdaptiveMap_Concurrent(t *testing.T) {
+	m := NewAdaptiveMap[int, int](WithAdaptiveWindow(100))
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := g*2000 + i
+				m.Store(key, key)
+				if val, ok := m.Load(key); !ok || val != key {
+					t.Errorf("Expected %d, but got %d, %v", key, val, ok)
+					return
+				}
+				if i%500 == 0 {
+					m.Range(func(key, val int) bool { return true })
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if m.Len() != 16_000 {
+		t.Errorf("Expected 16000, but got %d", m.Len())
+	}
+}
@@ -0,0 +1,100 @@
+package gomap
+
+type arcPolicy[K comparable] struct {
+	capacity int
+	p        int // target size of t1
+
+	t1, t2 *keyList[K] // resident keys seen once / at least twice
+	b1, b2 *keyList[K] // ghosts of the keys recently evicted from t1 / t2
+
+	insertedFromB2 bool
+}
+
+// NewARCPolicy evicts with the Adaptive Replacement Cache algorithm,
+// which balances recency and frequency by remembering the keys it evicted.
+// capacity must be the capacity of the map.
+func NewARCPolicy[K comparable](capacity int) EvictionPolicy[K] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &arcPolicy[K]{
+		capacity: capacity,
+		t1:       newKeyList[K](),
+		t2:       newKeyList[K](),
+		b1:       newKeyList[K](),
+		b2:       newKeyList[K](),
+	}
+}
+
+func (p *arcPolicy[K]) Access(key K) {
+	if p.t1.Remove(key) {
+		p.t2.PushFront(key)
+		return
+	}
+	if p.t2.Contain(key) {
+		p.t2.MoveToFront(key)
+	}
+}
+
+func (p *arcPolicy[K]) Insert(key K) {
+	p.insertedFromB2 = false
+
+	switch {
+	case p.b1.Contain(key):
+		// Recency was undervalued, grow t1
+		p.p = min(p.capacity, p.p+max(p.b2.Len()/p.b1.Len(), 1))
+		p.b1.Remove(key)
+		p.t2.PushFront(key)
+	case p.b2.Contain(key):
+		// Frequency was undervalued, shrink t1
+		p.p = max(0, p.p-max(p.b1.Len()/p.b2.Len(), 1))
+		p.b2.Remove(key)
+		p.t2.PushFront(key)
+		p.insertedFromB2 = true
+	default:
+		p.t1.PushFront(key)
+	}
+}
+
+func (p *arcPolicy[K]) Remove(key K) {
+	if !p.t1.Remove(key) {
+		p.t2.Remove(key)
+	}
+}
+
+func (p *arcPolicy[K]) Victim() (K, bool) {
+	var key K
+	var ok bool
+
+	t1Len := p.t1.Len()
+	if t1Len > 0 && (t1Len > p.p || (p.insertedFromB2 && t1Len == p.p) || p.t2.Len() == 0) {
+		key, ok = p.t1.PopBack()
+		p.b1.PushFront(key)
+	} else {
+		key, ok = p.t2.PopBack()
+		if ok {
+			p.b2.PushFront(key)
+		}
+	}
+	p.trimGhosts()
+	return key, ok
+}
+
+// trimGhosts keeps t1+b1 within the capacity and all the lists within twice of it
+func (p *arcPolicy[K]) trimGhosts() {
+	for p.b1.Len() > 0 && p.t1.Len()+p.b1.Len() > p.capacity {
+		p.b1.PopBack()
+	}
+	for p.b2.Len() > 0 && p.t1.Len()+p.t2.Len()+p.b1.Len()+p.b2.Len() > 2*p.capacity {
+		p.b2.PopBack()
+	}
+}
+
+func (p *arcPolicy[K]) Reset() {
+	p.p = 0
+	p.t1.Reset()
+	p.t2.Reset()
+	p.b1.Reset()
+	p.b2.Reset()
+	p.insertedFromB2 = false
+}
@@ -0,0 +1,123 @@
+package gomap_test
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/lovung/gomap"
+)
+
+// benchRecords is the number of keys stored before the benchmarks reading or updating them
+const benchRecords = 1 << 14
+
+var benchBackends = []struct {
+	name       string
+	threadSafe bool
+	factory    func() gomap.Map[int, string]
+}{
+	{"PureMap", false, func() gomap.Map[int, string] { return gomap.NewPureMap[int, string]() }},
+	{"ThreadSafePureMap", true, func() gomap.Map[int, string] { return gomap.NewThreadSafePureMap[int, string]() }},
+	{"SyncMap", true, func() gomap.Map[int, string] { return gomap.NewSyncMap[int, string]() }},
+	{"SortedSliceMap", false, func() gomap.Map[int, string] { return gomap.NewSortedSliceMap[int, string]() }},
+	{"ThreadSafeSortedSliceMap", true, func() gomap.Map[int, string] { return gomap.NewThreadSafeSortedSliceMap[int, string]() }},
+	{"IntSortedSliceMap", false, func() gomap.Map[int, string] { return gomap.NewIntSortedSliceMap[int, string]() }},
+	{"ThreadSafeIntSortedSliceMap", true, func() gomap.Map[int, string] { return gomap.NewThreadSafeIntSortedSliceMap[int, string]() }},
+	{"LinkedMap", false, func() gomap.Map[int, string] { return gomap.NewLinkedMap[int, string]() }},
+	{"LRUMap", false, func() gomap.Map[int, string] { return gomap.NewLRUMap[int, string](1 << 30) }},
+	{"ThreadSafeLRUMap", true, func() gomap.Map[int, string] { return gomap.NewThreadSafeLRUMap[int, string](1 << 30) }},
+	{"MVCCMap", true, func() gomap.Map[int, string] { return gomap.NewMVCCMap[int, string]() }},
+}
+
+// benchMap returns a map of factory holding the keys [0, benchRecords)
+func benchMap(factory func() gomap.Map[int, string]) gomap.Map[int, string] {
+	m := factory()
+	for i := 0; i < benchRecords; i++ {
+		m.Store(i, strconv.Itoa(i))
+	}
+	return m
+}
+
+func BenchmarkLoad(b *testing.B) {
+	for _, bb := range benchBackends {
+		b.Run(bb.name, func(b *testing.B) {
+			m := benchMap(bb.factory)
+			r := rand.New(rand.NewSource(1))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.Load(r.Intn(benchRecords))
+			}
+		})
+	}
+}
+
+func BenchmarkLoadMiss(b *testing.B) {
+	for _, bb := range benchBackends {
+		b.Run(bb.name, func(b *testing.B) {
+			m := benchMap(bb.factory)
+			r := rand.New(rand.NewSource(1))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.Load(benchRecords + r.Intn(benchRecords))
+			}
+		})
+	}
+}
+
+func BenchmarkStore(b *testing.B) {
+	for _, bb := range benchBackends {
+		b.Run(bb.name, func(b *testing.B) {
+			m := benchMap(bb.factory)
+			r := rand.New(rand.NewSource(1))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.Store(r.Intn(benchRecords), "value")
+			}
+		})
+	}
+}
+
+// BenchmarkStoreDelete inserts and removes keys, which moves the items of the sorted maps
+func BenchmarkStoreDelete(b *testing.B) {
+	for _, bb := range benchBackends {
+		b.Run(bb.name, func(b *testing.B) {
+			m := benchMap(bb.factory)
+			r := rand.New(rand.NewSource(1))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := benchRecords + r.Intn(benchRecords)
+				m.Store(key, "value")
+				m.Delete(key)
+			}
+		})
+	}
+}
+
+// BenchmarkParallelReadMostly runs 95% Load and 5% Store on the thread-safe maps from GOMAXPROCS goroutines
+func BenchmarkParallelReadMostly(b *testing.B) {
+	for _, bb := range benchBackends {
+		if !bb.threadSafe {
+			continue
+		}
+		b.Run(bb.name, func(b *testing.B) {
+			m := benchMap(bb.factory)
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				r := rand.New(rand.NewSource(rand.Int63()))
+				for pb.Next() {
+					key := r.Intn(benchRecords)
+					if r.Intn(100) < 5 {
+						m.Store(key, "value")
+					} else {
+						m.Load(key)
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package gomap
+
+import (
+	"bytes"
+	"io"
+)
+
+// marshalBinary encodes m as a snapshot with the default codecs
+func marshalBinary(m io.WriterTo) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalBinary replaces the content of m with the snapshot in data,
+// which must not hold anything after the snapshot
+func unmarshalBinary(data []byte, m io.ReaderFrom) error {
+	n, err := m.ReadFrom(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if n != int64(len(data)) {
+		return ErrBadSnapshot
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package gomap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"errors"
+	"testing"
+)
+
+func TestBinary_Backends(t *testing.T) {
+	factories := map[string]func() Map[int, string]{
+		"PureMap":                     func() Map[int, string] { return NewPureMap[int, string]() },
+		"ThreadSafePureMap":           func() Map[int, string] { return NewThreadSafePureMap[int, string]() },
+		"SyncMap":                     func() Map[int, string] { return NewSyncMap[int, string]() },
+		"SortedSliceMap":              func() Map[int, string] { return NewSortedSliceMap[int, string]() },
+		"ThreadSafeSortedSliceMap":    func() Map[int, string] { return NewThreadSafeSortedSliceMap[int, string]() },
+		"IntSortedSliceMap":           func() Map[int, string] { return NewIntSortedSliceMap[int, string]() },
+		"ThreadSafeIntSortedSliceMap": func() Map[int, string] { return NewThreadSafeIntSortedSliceMap[int, string]() },
+		"LinkedMap":                   func() Map[int, string] { return NewLinkedMap[int, string]() },
+		"LRUMap":                      func() Map[int, string] { return NewLRUMap[int, string](10) },
+		"ThreadSafeLRUMap":            func() Map[int, string] { return NewThreadSafeLRUMap[int, string](10) },
+		"MVCCMap":                     func() Map[int, string] { return NewMVCCMap[int, string]() },
+	}
+	for name, factory := range factories {
+		m := factory()
+		m.Store(1, "one")
+		m.Store(2, "two")
+
+		data, err := m.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: MarshalBinary: Expected no error, but got %v", name, err)
+		}
+		decoded := factory()
+		if err := decoded.(encoding.BinaryUnmarshaler).UnmarshalBinary(data); err != nil {
+			t.Fatalf("%s: UnmarshalBinary: Expected no error, but got %v", name, err)
+		}
+		if val, _ := decoded.Load(2); val != "two" || decoded.(Iterable[int, string]).Len() != 2 {
+			t.Errorf("%s: UnmarshalBinary: Expected 2 entries, but got %v", name, entriesOf(decoded))
+		}
+
+		if err := decoded.(encoding.BinaryUnmarshaler).UnmarshalBinary(append(data, 0)); !errors.Is(err, ErrBadSnapshot) {
+			t.Errorf("%s: UnmarshalBinary: Expected ErrBadSnapshot on trailing data, but got %v", name, err)
+		}
+	}
+}
+
+type gobMessage struct {
+	Name  string
+	Index Map[int, string]
+}
+
+func TestBinary_Gob(t *testing.T) {
+	// gob decodes an interface into a new zero value of the registered type
+	gob.Register(NewPureMap[int, string]())
+	gob.Register(NewLinkedMap[int, string]())
+	gob.Register(NewThreadSafeLRUMap[int, string](1))
+	gob.Register(NewMVCCMap[int, string]())
+
+	backends := []Map[int, string]{
+		NewPureMap[int, string](),
+		NewLinkedMap[int, string](),
+		NewThreadSafeLRUMap[int, string](10),
+		NewMVCCMap[int, string](),
+	}
+	for _, m := range backends {
+		for _, key := range []int{3, 1, 2} {
+			m.Store(key, "v")
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(gobMessage{Name: "index", Index: m}); err != nil {
+			t.Fatalf("%T: Encode: Expected no error, but got %v", m, err)
+		}
+		var msg gobMessage
+		if err := gob.NewDecoder(&buf).Decode(&msg); err != nil {
+			t.Fatalf("%T: Decode: Expected no error, but got %v", m, err)
+		}
+		if msg.Name != "index" || msg.Index == nil {
+			t.Fatalf("%T: Decode: Expected the message back, but got %+v", m, msg)
+		}
+		if got := entriesOf(msg.Index); len(got) != 3 {
+			t.Errorf("%T: Decode: Expected %v, but got %v", m, entriesOf(m), got)
+		}
+
+		// The decoded map is fully usable
+		msg.Index.Store(4, "v")
+		msg.Index.Delete(1)
+		if !msg.Index.Contain(4) || msg.Index.Contain(1) || msg.Index.(Iterable[int, string]).Len() != 3 {
+			t.Errorf("%T: Expected the decoded map to be writable, but got %v", m, entriesOf(msg.Index))
+		}
+	}
+
+	// The insertion order survives
+	linked := NewLinkedMap[int, string]()
+	for _, key := range []int{3, 1, 2} {
+		linked.Store(key, "v")
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gobMessage{Index: linked}); err != nil {
+		t.Fatalf("Encode: Expected no error, but got %v", err)
+	}
+	var msg gobMessage
+	if err := gob.NewDecoder(&buf).Decode(&msg); err != nil {
+		t.Fatalf("Decode: Expected no error, but got %v", err)
+	}
+	if oldest, _, _ := msg.Index.(LinkedMap[int, string]).Oldest(); oldest != 3 {
+		t.Errorf("Decode: Expected the oldest key to be 3, but got %d", oldest)
+	}
+}
+
+func TestBinary_Settings(t *testing.T) {
+	// gob decodes into a zero value, which takes the settings of the snapshot
+	gob.Register(NewLRUMap[int, string](1))
+
+	lru := NewLRUMap[int, string](2, WithMaxCost(5))
+	threadSafe := NewThreadSafeLRUMap[int, string](100, WithMaxCost(50), WithShards(4))
+	for _, m := range []BoundedMap[int, string]{lru, threadSafe} {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(gobMessage{Index: m}); err != nil {
+			t.Fatalf("%T: Encode: Expected no error, but got %v", m, err)
+		}
+		var msg gobMessage
+		if err := gob.NewDecoder(&buf).Decode(&msg); err != nil {
+			t.Fatalf("%T: Decode: Expected no error, but got %v", m, err)
+		}
+		decoded := msg.Index.(BoundedMap[int, string])
+		if decoded.Cap() != m.Cap() || decoded.MaxCost() != m.MaxCost() {
+			t.Errorf("%T: Expected cap %d and max cost %d, but got %d and %d", m, m.Cap(), m.MaxCost(), decoded.Cap(), decoded.MaxCost())
+		}
+		for i := 0; i < 200; i++ {
+			decoded.Store(i, "v")
+		}
+		if decoded.Len() > m.Cap() {
+			t.Errorf("%T: Expected at most %d entries, but got %d", m, m.Cap(), decoded.Len())
+		}
+	}
+	var buf bytes.Buffer
+	gob.NewEncoder(&buf).Encode(gobMessage{Index: threadSafe})
+	var msg gobMessage
+	gob.NewDecoder(&buf).Decode(&msg)
+	if shards := len(msg.Index.(*threadSafeLRUMap[int, string]).shards); shards != 4 {
+		t.Errorf("Decode: Expected 4 shards, but got %d", shards)
+	}
+
+	linked := NewLinkedMap[int, string](WithAccessOrder())
+	linked.Store(1, "one")
+	linked.Store(2, "two")
+	data, err := linked.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: Expected no error, but got %v", err)
+	}
+	decodedLinked := &linkedMap[int, string]{}
+	if err := decodedLinked.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: Expected no error, but got %v", err)
+	}
+	decodedLinked.Load(1)
+	if newest, _, _ := decodedLinked.Newest(); newest != 1 {
+		t.Errorf("UnmarshalBinary: Expected the access order to move key 1 to the back, but got %d", newest)
+	}
+
+	// A zero value can't decode a snapshot without the settings
+	data, _ = NewPureMap[int, string]().MarshalBinary()
+	if err := (&lruMap[int, string]{}).UnmarshalBinary(data); !errors.Is(err, ErrBadSnapshot) {
+		t.Errorf("UnmarshalBinary: Expected ErrBadSnapshot, but got %v", err)
+	}
+	if err := (&threadSafeLRUMap[int, string]{}).UnmarshalBinary(data); !errors.Is(err, ErrBadSnapshot) {
+		t.Errorf("UnmarshalBinary: Expected ErrBadSnapshot, but got %v", err)
+	}
+	if err := (&linkedMap[int, string]{}).UnmarshalBinary(data); !errors.Is(err, ErrBadSnapshot) {
+		t.Errorf("UnmarshalBinary: Expected ErrBadSnapshot, but got %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package gomap
+
+import "time"
+
+// Clock tells the time to the maps which expire entries,
+// it can be replaced to make them deterministic in tests
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// WithClock sets the clock used to expire entries, the system clock by default
+func WithClock(c Clock) Option {
+	return func(o *option) {
+		o.clock = c
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/lovung/gomap"
+)
+
+// backend is a map implementation the workloads run against
+type backend[K comparable, V any] struct {
+	name       string
+	threadSafe bool
+	new        func() gomap.Map[K, V]
+}
+
+// backends returns the registered backends supporting maps of K to V,
+// the bounded ones holding capacity entries
+func backends[K comparable, V any](capacity int) []backend[K, V] {
+	var bs []backend[K, V]
+	for _, info := range gomap.Backends() {
+		var opts []gomap.Option
+		if info.Capabilities.Has(gomap.CapBounded) {
+			opts = append(opts, gomap.WithCap(capacity))
+		}
+		if _, err := gomap.New[K, V](info.Name, opts...); err != nil {
+			continue
+		}
+		name := info.Name
+		bs = append(bs, backend[K, V]{
+			name:       name,
+			threadSafe: info.Capabilities.Has(gomap.CapThreadSafe),
+			new: func() gomap.Map[K, V] {
+				m, _ := gomap.New[K, V](name, opts...)
+				return m
+			},
+		})
+	}
+	return bs
+}
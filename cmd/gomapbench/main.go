@@ -0,0 +1,200 @@
+// Command gomapbench runs YCSB-style workloads against the gomap backends
+// and prints their throughput, latency and allocations.
+//
+// Usage:
+//
+//	gomapbench [flags]
+//
+// For example, the read mostly workload with Zipfian string keys on 1 and 8 goroutines:
+//
+//	gomapbench -workload b -keys string -goroutines 1,8
+//
+// It runs every backend registered in gomap, the ones which are not thread-safe only on one goroutine.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/exp/constraints"
+)
+
+type config struct {
+	workload   Workload
+	goroutines []int
+	backends   []string // nil means all
+	keys       string
+	values     string
+	format     string
+}
+
+func main() {
+	cfg, err := parseFlags(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gomapbench:", err)
+		os.Exit(2)
+	}
+	results, err := runConfig(cfg, os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gomapbench:", err)
+		os.Exit(1)
+	}
+	if err := report(os.Stdout, cfg, results); err != nil {
+		fmt.Fprintln(os.Stderr, "gomapbench:", err)
+		os.Exit(1)
+	}
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) (config, error) {
+	var (
+		cfg        config
+		preset     = fs.String("workload", "b", "YCSB preset: a (50% read, 50% write), b (95/5), c (read only), w (10/90) or d (80% read, 20% delete)")
+		read       = fs.Int("read", 0, "percentage of Load, overrides the preset with -write and -delete")
+		write      = fs.Int("write", 0, "percentage of Store, overrides the preset with -read and -delete")
+		del        = fs.Int("delete", 0, "percentage of Delete, overrides the preset with -read and -write")
+		goroutines = fs.String("goroutines", "1", "comma separated goroutine counts, a run per count")
+		backends   = fs.String("backends", "all", "comma separated backends to run")
+	)
+	fs.IntVar(&cfg.workload.Records, "records", 100_000, "number of keys stored before the run")
+	fs.IntVar(&cfg.workload.Ops, "ops", 1_000_000, "number of operations of a run")
+	fs.StringVar((*string)(&cfg.workload.Dist), "dist", string(Zipfian), "distribution of the keys: uniform or zipfian")
+	fs.Float64Var(&cfg.workload.ZipfS, "zipf-s", 1.1, "skew of the Zipfian distribution, > 1")
+	fs.IntVar(&cfg.workload.ValueSize, "value-size", 100, "size of the values in bytes")
+	fs.Int64Var(&cfg.workload.Seed, "seed", 1, "seed of the generated operations")
+	fs.StringVar(&cfg.keys, "keys", "int", "type of the keys: int or string")
+	fs.StringVar(&cfg.values, "values", "bytes", "type of the values: bytes or string")
+	fs.StringVar(&cfg.format, "format", "table", "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	p, ok := presets[*preset]
+	if !ok {
+		return cfg, fmt.Errorf("unknown workload %q", *preset)
+	}
+	mix := false
+	fs.Visit(func(f *flag.Flag) {
+		mix = mix || f.Name == "read" || f.Name == "write" || f.Name == "delete"
+	})
+	if mix {
+		p = Workload{Read: *read, Write: *write, Delete: *del}
+	}
+	cfg.workload.Read, cfg.workload.Write, cfg.workload.Delete = p.Read, p.Write, p.Delete
+
+	for _, s := range strings.Split(*goroutines, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n <= 0 {
+			return cfg, fmt.Errorf("bad goroutine count %q", s)
+		}
+		cfg.goroutines = append(cfg.goroutines, n)
+	}
+	if *backends != "all" {
+		for _, name := range strings.Split(*backends, ",") {
+			cfg.backends = append(cfg.backends, strings.TrimSpace(name))
+		}
+	}
+	if cfg.keys != "int" && cfg.keys != "string" {
+		return cfg, fmt.Errorf("unknown key type %q", cfg.keys)
+	}
+	if cfg.values != "bytes" && cfg.values != "string" {
+		return cfg, fmt.Errorf("unknown value type %q", cfg.values)
+	}
+	if cfg.format != "table" && cfg.format != "json" {
+		return cfg, fmt.Errorf("unknown format %q", cfg.format)
+	}
+	w := cfg.workload
+	w.Goroutines = 1
+	return cfg, w.validate()
+}
+
+// runConfig runs the workload for every goroutine count and backend, logging the skipped runs to log
+func runConfig(cfg config, log io.Writer) ([]Result, error) {
+	bytesValue := func(i int) []byte {
+		v := make([]byte, cfg.workload.ValueSize)
+		copy(v, strconv.Itoa(i))
+		return v
+	}
+	stringValue := func(i int) string {
+		return string(bytesValue(i))
+	}
+	switch {
+	case cfg.keys == "int" && cfg.values == "bytes":
+		return runBackends(cfg, log, backends[int64, []byte](cfg.workload.Records), intKeys(cfg.workload), bytesValue)
+	case cfg.keys == "int":
+		return runBackends(cfg, log, backends[int64, string](cfg.workload.Records), intKeys(cfg.workload), stringValue)
+	case cfg.values == "bytes":
+		return runBackends(cfg, log, backends[string, []byte](cfg.workload.Records), stringKeys(cfg.workload), bytesValue)
+	default:
+		return runBackends(cfg, log, backends[string, string](cfg.workload.Records), stringKeys(cfg.workload), stringValue)
+	}
+}
+
+func runBackends[K constraints.Ordered, V any](cfg config, log io.Writer, all []backend[K, V], keys []K, newValue func(i int) V) ([]Result, error) {
+	selected := all
+	if cfg.backends != nil {
+		byName := make(map[string]backend[K, V], len(all))
+		var names []string
+		for _, b := range all {
+			byName[b.name] = b
+			names = append(names, b.name)
+		}
+		selected = nil
+		for _, name := range cfg.backends {
+			b, ok := byName[name]
+			if !ok {
+				sort.Strings(names)
+				return nil, fmt.Errorf("unknown backend %q for %s keys, expected one of %s", name, cfg.keys, strings.Join(names, ", "))
+			}
+			selected = append(selected, b)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, errors.New("no backend to run")
+	}
+
+	var results []Result
+	for _, g := range cfg.goroutines {
+		w := cfg.workload
+		w.Goroutines = g
+		for _, b := range selected {
+			if !supported(w, b) {
+				fmt.Fprintf(log, "skip %s on %d goroutines: not thread-safe\n", b.name, g)
+				continue
+			}
+			r := run(w, b, keys, newValue)
+			r.Keys, r.Values = cfg.keys, cfg.values
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+func report(out io.Writer, cfg config, results []Result) error {
+	if cfg.format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Workload Workload `json:"workload"`
+			Results  []Result `json:"results"`
+		}{cfg.workload, results})
+	}
+
+	w := cfg.workload
+	fmt.Fprintf(out, "%d records, %d ops, %d%% read %d%% write %d%% delete, %s %s keys, %d byte %s values\n\n",
+		w.Records, w.Ops, w.Read, w.Write, w.Delete, w.Dist, cfg.keys, w.ValueSize, cfg.values)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "backend\tgoroutines\tops/s\tp50\tp99\tallocs/op\tB/op\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%.0f\t%v\t%v\t%.2f\t%.1f\t\n",
+			r.Backend, r.Goroutines, r.OpsPerSec, r.P50, r.P99, r.AllocsPerOp, r.BytesPerOp)
+	}
+	return tw.Flush()
+}
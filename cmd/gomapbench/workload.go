@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Distribution is how the keys of the operations are picked
+type Distribution string
+
+const (
+	Uniform Distribution = "uniform"
+	Zipfian Distribution = "zipfian"
+)
+
+// Workload is a YCSB-style mix of operations on a preloaded map
+type Workload struct {
+	Records    int          `json:"records"`    // number of keys, all stored before the run
+	Ops        int          `json:"ops"`        // number of operations of a run, shared by the goroutines
+	Goroutines int          `json:"-"`          // number of goroutines running the operations
+	Read       int          `json:"read"`       // percentage of Load
+	Write      int          `json:"write"`      // percentage of Store
+	Delete     int          `json:"delete"`     // percentage of Delete
+	Dist       Distribution `json:"dist"`       // distribution of the keys
+	ZipfS      float64      `json:"zipf_s"`     // skew of the Zipfian distribution, > 1
+	ValueSize  int          `json:"value_size"` // size of the values in bytes
+	Seed       int64        `json:"seed"`
+}
+
+// presets are the YCSB core workloads which only use Load and Store
+var presets = map[string]Workload{
+	"a": {Read: 50, Write: 50},  // update heavy
+	"b": {Read: 95, Write: 5},   // read mostly
+	"c": {Read: 100},            // read only
+	"w": {Read: 10, Write: 90},  // write heavy
+	"d": {Read: 80, Delete: 20}, // read and delete
+}
+
+func (w Workload) validate() error {
+	switch {
+	case w.Records <= 0 || w.Ops <= 0 || w.Goroutines <= 0:
+		return fmt.Errorf("records, ops and goroutines must be positive")
+	case w.Read < 0 || w.Write < 0 || w.Delete < 0 || w.Read+w.Write+w.Delete != 100:
+		return fmt.Errorf("read, write and delete must be percentages adding up to 100, got %d+%d+%d", w.Read, w.Write, w.Delete)
+	case w.Dist != Uniform && w.Dist != Zipfian:
+		return fmt.Errorf("unknown distribution %q", w.Dist)
+	case w.Dist == Zipfian && w.ZipfS <= 1:
+		return fmt.Errorf("zipfian skew must be > 1, got %v", w.ZipfS)
+	case w.ValueSize < 0:
+		return fmt.Errorf("value size must not be negative")
+	}
+	return nil
+}
+
+// Result is the measure of a workload run against a backend
+type Result struct {
+	Backend     string        `json:"backend"`
+	Keys        string        `json:"keys"`
+	Values      string        `json:"values"`
+	Goroutines  int           `json:"goroutines"`
+	Ops         int           `json:"ops"`
+	Elapsed     time.Duration `json:"elapsed_ns"`
+	OpsPerSec   float64       `json:"ops_per_sec"`
+	P50         time.Duration `json:"p50_ns"`
+	P99         time.Duration `json:"p99_ns"`
+	AllocsPerOp float64       `json:"allocs_per_op"`
+	BytesPerOp  float64       `json:"bytes_per_op"`
+}
+
+type opKind uint8
+
+const (
+	opRead opKind = iota
+	opWrite
+	opDelete
+)
+
+type op struct {
+	kind opKind
+	key  int // index in the keys
+}
+
+// plan generates the operations of every goroutine before the run,
+// so generating them is not measured
+func (w Workload) plan() [][]op {
+	plans := make([][]op, w.Goroutines)
+	for g := range plans {
+		r := rand.New(rand.NewSource(w.Seed + int64(g)))
+		var zipf *rand.Zipf
+		if w.Dist == Zipfian {
+			zipf = rand.NewZipf(r, w.ZipfS, 1, uint64(w.Records-1))
+		}
+		n := w.Ops / w.Goroutines
+		if g < w.Ops%w.Goroutines {
+			n++
+		}
+		ops := make([]op, n)
+		for i := range ops {
+			switch p := r.Intn(100); {
+			case p < w.Read:
+				ops[i].kind = opRead
+			case p < w.Read+w.Write:
+				ops[i].kind = opWrite
+			default:
+				ops[i].kind = opDelete
+			}
+			if zipf != nil {
+				ops[i].key = int(zipf.Uint64())
+			} else {
+				ops[i].key = r.Intn(w.Records)
+			}
+		}
+		plans[g] = ops
+	}
+	return plans
+}
+
+// intKeys returns the keys of the records, shuffled so the hot keys of the Zipfian distribution are spread
+func intKeys(w Workload) []int64 {
+	keys := make([]int64, w.Records)
+	for i := range keys {
+		keys[i] = int64(i)
+	}
+	rand.New(rand.NewSource(w.Seed)).Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+	return keys
+}
+
+func stringKeys(w Workload) []string {
+	keys := make([]string, w.Records)
+	for i, k := range intKeys(w) {
+		keys[i] = "user" + strconv.FormatInt(k, 10)
+	}
+	return keys
+}
+
+// run preloads a map of b with the keys then runs the workload on it.
+// newValue returns the value stored by the i-th write.
+func run[K constraints.Ordered, V any](w Workload, b backend[K, V], keys []K, newValue func(i int) V) Result {
+	m := b.new()
+	// Preload in order of the keys, the way a sorted map is loaded the fastest
+	sorted := slices.Clone(keys)
+	slices.Sort(sorted)
+	for i, k := range sorted {
+		m.Store(k, newValue(i))
+	}
+	values := make([]V, 64)
+	for i := range values {
+		values[i] = newValue(i)
+	}
+
+	plans := w.plan()
+	latencies := make([][]time.Duration, w.Goroutines)
+	var (
+		start, done sync.WaitGroup
+		before      runtime.MemStats
+		after       runtime.MemStats
+	)
+	start.Add(1)
+	for g, ops := range plans {
+		done.Add(1)
+		lat := make([]time.Duration, len(ops))
+		latencies[g] = lat
+		go func(ops []op) {
+			defer done.Done()
+			start.Wait()
+			for i, o := range ops {
+				t := time.Now()
+				switch o.kind {
+				case opRead:
+					m.Load(keys[o.key])
+				case opWrite:
+					m.Store(keys[o.key], values[i%len(values)])
+				case opDelete:
+					m.Delete(keys[o.key])
+				}
+				lat[i] = time.Since(t)
+			}
+		}(ops)
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	begin := time.Now()
+	start.Done()
+	done.Wait()
+	elapsed := time.Since(begin)
+	runtime.ReadMemStats(&after)
+
+	var all []time.Duration
+	for _, lat := range latencies {
+		all = append(all, lat...)
+	}
+	slices.Sort(all)
+	ops := len(all)
+	return Result{
+		Backend:     b.name,
+		Goroutines:  w.Goroutines,
+		Ops:         ops,
+		Elapsed:     elapsed,
+		OpsPerSec:   float64(ops) / elapsed.Seconds(),
+		P50:         percentile(all, 0.50),
+		P99:         percentile(all, 0.99),
+		AllocsPerOp: float64(after.Mallocs-before.Mallocs) / float64(ops),
+		BytesPerOp:  float64(after.TotalAlloc-before.TotalAlloc) / float64(ops),
+	}
+}
+
+// percentile returns the p-th percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(p*float64(len(sorted)-1))]
+}
+
+// supported tells if b can run w, the maps which are not thread-safe only run on one goroutine
+func supported[K comparable, V any](w Workload, b backend[K, V]) bool {
+	return b.threadSafe || w.Goroutines == 1
+}
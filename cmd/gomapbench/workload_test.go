@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWorkload_Plan(t *testing.T) {
+	w := Workload{Records: 100, Ops: 10_001, Goroutines: 3, Read: 60, Write: 30, Delete: 10, Dist: Zipfian, ZipfS: 1.2, Seed: 1}
+	plans := w.plan()
+	counts := make(map[opKind]int)
+	total := 0
+	for _, ops := range plans {
+		total += len(ops)
+		for _, o := range ops {
+			counts[o.kind]++
+			if o.key < 0 || o.key >= w.Records {
+				t.Fatalf("Expected a key index in [0, %d), but got %d", w.Records, o.key)
+			}
+		}
+	}
+	if total != w.Ops {
+		t.Errorf("Expected %d ops, but got %d", w.Ops, total)
+	}
+	for kind, want := range map[opKind]int{opRead: 60, opWrite: 30, opDelete: 10} {
+		if got := counts[kind] * 100 / total; got < want-3 || got > want+3 {
+			t.Errorf("Expected about %d%% of op %d, but got %d%%", want, kind, got)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if got := percentile(sorted, 0.5); got != 5 {
+		t.Errorf("Expected 5, but got %v", got)
+	}
+	if got := percentile(sorted, 0.99); got != 9 {
+		t.Errorf("Expected 9, but got %v", got)
+	}
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Errorf("Expected 0, but got %v", got)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{args: nil},
+		{args: []string{"-workload", "a", "-goroutines", "1,2", "-keys", "string"}},
+		{args: []string{"-read", "70", "-write", "20", "-delete", "10"}},
+		{args: []string{"-read", "70"}, wantErr: true},
+		{args: []string{"-workload", "z"}, wantErr: true},
+		{args: []string{"-goroutines", "0"}, wantErr: true},
+		{args: []string{"-dist", "zipfian", "-zipf-s", "1"}, wantErr: true},
+		{args: []string{"-format", "xml"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("gomapbench", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		if _, err := parseFlags(fs, tt.args); (err != nil) != tt.wantErr {
+			t.Errorf("%v: Expected error %v, but got %v", tt.args, tt.wantErr, err)
+		}
+	}
+}
+
+func TestRunConfig(t *testing.T) {
+	for _, keys := range []string{"int", "string"} {
+		fs := flag.NewFlagSet("gomapbench", flag.ContinueOnError)
+		cfg, err := parseFlags(fs, []string{"-records", "50", "-ops", "500", "-goroutines", "1,2", "-workload", "a", "-keys", keys, "-format", "json"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := runConfig(cfg, io.Discard)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all := len(backends[string, string](cfg.workload.Records))
+		if keys == "int" {
+			all = len(backends[int64, string](cfg.workload.Records))
+		}
+		if len(results) <= all {
+			t.Errorf("Expected the thread-safe backends to also run on 2 goroutines, but got %d results", len(results))
+		}
+		for _, r := range results {
+			if r.Ops != 500 || r.OpsPerSec <= 0 || r.P99 < r.P50 {
+				t.Errorf("Expected a measured run, but got %+v", r)
+			}
+		}
+
+		var out bytes.Buffer
+		if err := report(&out, cfg, results); err != nil {
+			t.Fatal(err)
+		}
+		var decoded struct{ Results []Result }
+		if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded.Results) != len(results) {
+			t.Errorf("Expected %d results in JSON, but got %d, %v", len(results), len(decoded.Results), err)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package gomap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"math"
+	"reflect"
+
+	"golang.org/x/exp/constraints"
+)
+
+// ErrShortBuffer is returned by a Codec when the buffer ends in the middle of a value
+var ErrShortBuffer = errors.New("gomap: short buffer")
+
+// Codec encodes the keys or the values of a map to bytes
+type Codec[T any] interface {
+	// Append appends the encoding of v to buf
+	Append(buf []byte, v T) ([]byte, error)
+	// Decode decodes a value at the start of buf and returns how many bytes it used
+	Decode(buf []byte) (T, int, error)
+}
+
+type fixedIntCodec[T constraints.Integer] struct{}
+
+// FixedIntCodec encodes integers on 8 bytes
+func FixedIntCodec[T constraints.Integer]() Codec[T] {
+	return fixedIntCodec[T]{}
+}
+
+func (fixedIntCodec[T]) Append(buf []byte, v T) ([]byte, error) {
+	return binary.LittleEndian.AppendUint64(buf, uint64(v)), nil
+}
+
+func (fixedIntCodec[T]) Decode(buf []byte) (T, int, error) {
+	if len(buf) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return T(binary.LittleEndian.Uint64(buf)), 8, nil
+}
+
+type varintCodec[T constraints.Integer] struct{}
+
+// VarintCodec encodes integers on 1 to 10 bytes, the small ones being the shortest
+func VarintCodec[T constraints.Integer]() Codec[T] {
+	return varintCodec[T]{}
+}
+
+func (varintCodec[T]) Append(buf []byte, v T) ([]byte, error) {
+	return binary.AppendVarint(buf, int64(v)), nil
+}
+
+func (varintCodec[T]) Decode(buf []byte) (T, int, error) {
+	v, n := binary.Varint(buf)
+	if n <= 0 {
+		return 0, 0, ErrShortBuffer
+	}
+	return T(v), n, nil
+}
+
+type stringCodec[T ~string] struct{}
+
+// StringCodec encodes strings prefixed by their length
+func StringCodec[T ~string]() Codec[T] {
+	return stringCodec[T]{}
+}
+
+func (stringCodec[T]) Append(buf []byte, v T) ([]byte, error) {
+	buf = binary.AppendUvarint(buf, uint64(len(v)))
+	return append(buf, v...), nil
+}
+
+func (stringCodec[T]) Decode(buf []byte) (T, int, error) {
+	size, n := binary.Uvarint(buf)
+	if n <= 0 || uint64(len(buf)-n) < size {
+		return "", 0, ErrShortBuffer
+	}
+	return T(buf[n : n+int(size)]), n + int(size), nil
+}
+
+type gobCodec[T any] struct{}
+
+// GobCodec encodes any value with encoding/gob prefixed by its length.
+// It is the fallback of the default codec, the other codecs are much more compact.
+func GobCodec[T any]() Codec[T] {
+	return gobCodec[T]{}
+}
+
+func (gobCodec[T]) Append(buf []byte, v T) ([]byte, error) {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(&v); err != nil {
+		return buf, err
+	}
+	buf = binary.AppendUvarint(buf, uint64(b.Len()))
+	return append(buf, b.Bytes()...), nil
+}
+
+func (gobCodec[T]) Decode(buf []byte) (T, int, error) {
+	var v T
+	size, n := binary.Uvarint(buf)
+	if n <= 0 || uint64(len(buf)-n) < size {
+		return v, 0, ErrShortBuffer
+	}
+	err := gob.NewDecoder(bytes.NewReader(buf[n : n+int(size)])).Decode(&v)
+	return v, n + int(size), err
+}
+
+// reflectCodec encodes the types whose kind is a number, a string or a bool,
+// including the named types which the typed codecs can't be instantiated with
+type reflectCodec[T any] struct {
+	kind reflect.Kind
+}
+
+func (c reflectCodec[T]) Append(buf []byte, v T) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	switch c.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.AppendVarint(buf, rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return binary.AppendUvarint(buf, rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(rv.Float())), nil
+	case reflect.Bool:
+		if rv.Bool() {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	default: // reflect.String
+		s := rv.String()
+		buf = binary.AppendUvarint(buf, uint64(len(s)))
+		return append(buf, s...), nil
+	}
+}
+
+func (c reflectCodec[T]) Decode(buf []byte) (T, int, error) {
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	switch c.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, n := binary.Varint(buf)
+		if n <= 0 {
+			return v, 0, ErrShortBuffer
+		}
+		rv.SetInt(x)
+		return v, n, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return v, 0, ErrShortBuffer
+		}
+		rv.SetUint(x)
+		return v, n, nil
+	case reflect.Float32, reflect.Float64:
+		if len(buf) < 8 {
+			return v, 0, ErrShortBuffer
+		}
+		rv.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(buf)))
+		return v, 8, nil
+	case reflect.Bool:
+		if len(buf) < 1 {
+			return v, 0, ErrShortBuffer
+		}
+		rv.SetBool(buf[0] != 0)
+		return v, 1, nil
+	default: // reflect.String
+		size, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < size {
+			return v, 0, ErrShortBuffer
+		}
+		rv.SetString(string(buf[n : n+int(size)]))
+		return v, n + int(size), nil
+	}
+}
+
+// DefaultCodec returns the codec used when none is given:
+// varints for integers, length-prefixed strings, and gob for the other types
+func DefaultCodec[T any]() Codec[T] {
+	switch any(*new(T)).(type) {
+	case int:
+		return any(VarintCodec[int]()).(Codec[T])
+	case int64:
+		return any(VarintCodec[int64]()).(Codec[T])
+	case uint64:
+		return any(VarintCodec[uint64]()).(Codec[T])
+	case string:
+		return any(StringCodec[string]()).(Codec[T])
+	}
+
+	switch kind := reflect.TypeOf((*T)(nil)).Elem().Kind(); kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Bool, reflect.String:
+		return reflectCodec[T]{kind: kind}
+	}
+	return GobCodec[T]()
+}
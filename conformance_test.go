@@ -0,0 +1,52 @@
+package gomap_test
+
+import (
+	"testing"
+
+	"github.com/lovung/gomap"
+	"github.com/lovung/gomap/gomaptest"
+)
+
+func TestConformance(t *testing.T) {
+	backends := []struct {
+		name    string
+		factory func() gomap.Map[int, string]
+		opts    []gomaptest.Option
+	}{
+		{"PureMap", func() gomap.Map[int, string] { return gomap.NewPureMap[int, string]() }, nil},
+		{"ThreadSafePureMap", func() gomap.Map[int, string] { return gomap.NewThreadSafePureMap[int, string]() },
+			[]gomaptest.Option{gomaptest.ThreadSafe()}},
+		{"SyncMap", func() gomap.Map[int, string] { return gomap.NewSyncMap[int, string]() },
+			[]gomaptest.Option{gomaptest.ThreadSafe()}},
+		{"SortedSliceMap", func() gomap.Map[int, string] { return gomap.NewSortedSliceMap[int, string]() },
+			[]gomaptest.Option{gomaptest.Ordered()}},
+		{"ThreadSafeSortedSliceMap", func() gomap.Map[int, string] { return gomap.NewThreadSafeSortedSliceMap[int, string]() },
+			[]gomaptest.Option{gomaptest.Ordered(), gomaptest.ThreadSafe()}},
+		{"IntSortedSliceMap", func() gomap.Map[int, string] { return gomap.NewIntSortedSliceMap[int, string]() },
+			[]gomaptest.Option{gomaptest.Ordered()}},
+		{"ThreadSafeIntSortedSliceMap", func() gomap.Map[int, string] { return gomap.NewThreadSafeIntSortedSliceMap[int, string]() },
+			[]gomaptest.Option{gomaptest.Ordered(), gomaptest.ThreadSafe()}},
+		{"LinkedMap", func() gomap.Map[int, string] { return gomap.NewLinkedMap[int, string]() }, nil},
+		{"LRUMap", func() gomap.Map[int, string] { return gomap.NewLRUMap[int, string](1 << 20) }, nil},
+		{"ThreadSafeLRUMap", func() gomap.Map[int, string] { return gomap.NewThreadSafeLRUMap[int, string](1 << 20) },
+			[]gomaptest.Option{gomaptest.ThreadSafe()}},
+		{"MVCCMap", func() gomap.Map[int, string] { return gomap.NewMVCCMap[int, string]() },
+			[]gomaptest.Option{gomaptest.ThreadSafe()}},
+		{"AdaptiveMap", func() gomap.Map[int, string] { return gomap.NewAdaptiveMap[int, string](gomap.WithAdaptiveWindow(16)) },
+			[]gomaptest.Option{gomaptest.Ordered(), gomaptest.ThreadSafe()}},
+		{"BoundedMap", func() gomap.Map[int, string] {
+			return gomap.NewBoundedMap[int, string](gomap.NewPureMap[int, string](), 1<<20, gomap.NewLRUPolicy[int]())
+		}, []gomaptest.Option{gomaptest.ThreadSafe()}},
+		{"LoadingMap", func() gomap.Map[int, string] {
+			return gomap.NewLoadingMap[int, string](gomap.NewThreadSafePureMap[int, string]())
+		}, nil},
+		{"TTLMap", func() gomap.Map[int, string] {
+			return gomap.NewTTLMap[int, string](gomap.NewSortedSliceMap[int, string]())
+		}, nil},
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			gomaptest.RunConformance(t, b.factory, b.opts...)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package gomap
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrCostExceeded is returned when an entry alone costs more than the budget of a map
+var ErrCostExceeded = errors.New("gomap: entry cost exceeds the max cost")
+
+// WithMaxCost bounds a map by the total cost of its entries instead of their number.
+// Each entry costs 1 unless WithCostFunc is given.
+func WithMaxCost(n int64) Option {
+	return func(o *option) {
+		o.maxCost = n
+	}
+}
+
+// WithCostFunc sets how much an entry costs against WithMaxCost,
+// K and V must be the types of the map
+func WithCostFunc[K comparable, V any](fn func(key K, val V) int64) Option {
+	return func(o *option) {
+		o.costFunc = fn
+	}
+}
+
+// costFuncOf returns the cost function of opt for a map of K and V
+func costFuncOf[K comparable, V any](opt option) func(key K, val V) int64 {
+	if opt.costFunc == nil {
+		return func(K, V) int64 { return 1 }
+	}
+	fn, ok := opt.costFunc.(func(key K, val V) int64)
+	if !ok {
+		panic(fmt.Sprintf("gomap: WithCostFunc got %T, expected func(%T, %T) int64", opt.costFunc, *new(K), *new(V)))
+	}
+	return fn
+}
+
+// capacityOf returns the entry capacity of a bounded map,
+// which is unlimited if the map is bounded by cost only
+func capacityOf(capacity int, opt option) int {
+	if capacity < 1 {
+		if opt.maxCost > 0 {
+			return math.MaxInt
+		}
+		return 1
+	}
+	return capacity
+}
+
+// headroom returns how much cost a map can still take
+func headroom(maxCost, cost int64) int64 {
+	if maxCost <= 0 {
+		return math.MaxInt64
+	}
+	return maxCost - cost
+}
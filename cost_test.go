@@ -0,0 +1,162 @@
+package gomap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMaxCost(t *testing.T) {
+	blobCost := WithCostFunc(func(key int, val []byte) int64 { return int64(len(val)) })
+	factories := map[string]func() BoundedMap[int, []byte]{
+		"LRUMap": func() BoundedMap[int, []byte] {
+			return NewLRUMap[int, []byte](0, WithMaxCost(100), blobCost)
+		},
+		"ThreadSafeLRUMap": func() BoundedMap[int, []byte] {
+			return NewThreadSafeLRUMap[int, []byte](0, WithMaxCost(100), blobCost, WithShards(1))
+		},
+		"BoundedMap": func() BoundedMap[int, []byte] {
+			return NewBoundedMap(NewPureMap[int, []byte](), 0, NewLRUPolicy[int](), WithMaxCost(100), blobCost)
+		},
+	}
+
+	for name, factory := range factories {
+		m := factory()
+		m.Store(1, make([]byte, 40))
+		m.Store(2, make([]byte, 40))
+		if m.Cost() != 80 || m.Headroom() != 20 || m.MaxCost() != 100 {
+			t.Errorf("%s: Expected cost 80 and headroom 20, but got %d and %d", name, m.Cost(), m.Headroom())
+		}
+
+		// Going over the budget evicts the least recently used entries
+		m.Store(3, make([]byte, 40))
+		if m.Contain(1) || !m.Contain(2) || !m.Contain(3) {
+			t.Errorf("%s: Expected key 1 to be evicted, but it wasn't", name)
+		}
+		if m.Cost() != 80 || m.Len() != 2 {
+			t.Errorf("%s: Expected cost 80 for 2 entries, but got %d for %d", name, m.Cost(), m.Len())
+		}
+
+		// Replacing an entry updates its cost
+		m.Store(3, make([]byte, 10))
+		if m.Cost() != 50 {
+			t.Errorf("%s: Expected cost 50, but got %d", name, m.Cost())
+		}
+
+		// An entry over the budget is rejected
+		if err := m.TryStore(4, make([]byte, 101)); !errors.Is(err, ErrCostExceeded) {
+			t.Errorf("%s: Expected ErrCostExceeded, but got %v", name, err)
+		}
+		m.Store(4, make([]byte, 101))
+		if m.Contain(4) || m.Len() != 2 {
+			t.Errorf("%s: Expected key 4 to be rejected, but it was stored", name)
+		}
+
+		m.Delete(2)
+		if m.Cost() != 10 {
+			t.Errorf("%s: Expected cost 10 after Delete, but got %d", name, m.Cost())
+		}
+		m.Clear()
+		if m.Cost() != 0 || m.Headroom() != 100 {
+			t.Errorf("%s: Expected cost 0 after Clear, but got %d", name, m.Cost())
+		}
+	}
+}
+
+func TestMaxCost_Overwrite(t *testing.T) {
+	factories := map[string]func() BoundedMap[int, int]{
+		"LRUMap": func() BoundedMap[int, int] {
+			return NewLRUMap[int, int](0, WithMaxCost(100), WithCostFunc(func(key, val int) int64 { return int64(val) }))
+		},
+		"ThreadSafeLRUMap": func() BoundedMap[int, int] {
+			return NewThreadSafeLRUMap[int, int](0, WithMaxCost(100), WithCostFunc(func(key, val int) int64 { return int64(val) }))
+		},
+		"BoundedMap": func() BoundedMap[int, int] {
+			return NewBoundedMap(NewPureMap[int, int](), 0, NewLRUPolicy[int](),
+				WithMaxCost(100), WithCostFunc(func(key, val int) int64 { return int64(val) }))
+		},
+	}
+
+	for name, factory := range factories {
+		m := factory()
+		var evicted []int
+		m.OnEvict(func(key, val int, reason EvictReason) {
+			evicted = append(evicted, val)
+		})
+		m.Store(1, 1)
+		// The value over the budget is rejected, and the old one must not be loaded in its place
+		if err := m.TryStore(1, 500); !errors.Is(err, ErrCostExceeded) {
+			t.Errorf("%s: Expected ErrCostExceeded, but got %v", name, err)
+		}
+		if val, ok := m.Load(1); ok {
+			t.Errorf("%s: Expected the old value to be evicted, but got %d", name, val)
+		}
+		if m.Cost() != 0 || len(evicted) != 1 || evicted[0] != 1 {
+			t.Errorf("%s: Expected the old value to be evicted at cost 0, but got %v at cost %d", name, evicted, m.Cost())
+		}
+	}
+}
+
+func TestMaxCost_Shards(t *testing.T) {
+	// The budget is shared by the default shards
+	m := NewThreadSafeLRUMap[int, int](0, WithMaxCost(100), WithCostFunc(func(key, val int) int64 { return int64(val) }))
+	if err := m.TryStore(1, 60); err != nil {
+		t.Fatalf("TryStore: Expected an entry under the max cost to be stored, but got %v", err)
+	}
+	if val, ok := m.Load(1); !ok || val != 60 || m.Headroom() != 40 {
+		t.Errorf("Load: Expected 60 with a headroom of 40, but got %d with %d", val, m.Headroom())
+	}
+	// Going over the total evicts the entry of another shard
+	m.Store(2, 60)
+	if m.Contain(1) || !m.Contain(2) || m.Cost() != 60 {
+		t.Errorf("Store: Expected key 1 to be evicted at cost 60, but got %v at cost %d", entriesOf[int, int](m), m.Cost())
+	}
+
+	// The least recently used entries of all the shards are evicted first
+	m.Clear()
+	for i := 0; i < 10; i++ {
+		m.Store(i, 10)
+	}
+	m.Load(0)
+	m.Store(10, 10)
+	m.Store(11, 20)
+	if !m.Contain(0) || m.Contain(1) || m.Contain(2) || m.Contain(3) || m.Len() != 9 {
+		t.Errorf("Store: Expected keys 1 to 3 to be evicted, but got %v", entriesOf[int, int](m))
+	}
+	for i := 0; i < 1000; i++ {
+		m.Store(i, 1+i%6)
+		if m.Cost() > m.MaxCost() {
+			t.Fatalf("Expected the cost to stay under %d, but got %d", m.MaxCost(), m.Cost())
+		}
+	}
+	if m.Cost() < m.MaxCost()-6 {
+		t.Errorf("Expected the evictions to keep the map full, but got a cost of %d", m.Cost())
+	}
+
+	m = NewThreadSafeLRUMap[int, int](0, WithMaxCost(3))
+	for i := 0; i < 10; i++ {
+		m.Store(i, i)
+	}
+	if m.Len() != 3 || m.Cost() != 3 {
+		t.Errorf("Expected 3 entries at cost 3, but got %d at cost %d", m.Len(), m.Cost())
+	}
+}
+
+func TestMaxCost_Unbounded(t *testing.T) {
+	m := NewLRUMap[int, int](2)
+	m.Store(1, 1)
+	if m.Cost() != 1 || m.MaxCost() != 0 {
+		t.Errorf("Cost: Expected every entry to cost 1 without cost limit, but got %d", m.Cost())
+	}
+	if m.Headroom() <= 0 {
+		t.Errorf("Headroom: Expected unlimited headroom, but got %d", m.Headroom())
+	}
+}
+
+func TestWithCostFunc_WrongTypes(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("WithCostFunc: Expected a panic for the wrong types, but got none")
+		}
+	}()
+	NewLRUMap[int, int](2, WithCostFunc(func(key string, val int) int64 { return 1 }))
+}
@@ -0,0 +1,81 @@
+package gomap
+
+import "fmt"
+
+// The time complexities of the operations, for n entries
+const (
+	constantTime    = "O(1)"
+	logarithmicTime = "O(log n)"
+	linearTime      = "O(n)"
+	sortTime        = "O(n log n)"
+	filteredTime    = "O(log n), O(1) for most missing keys"
+)
+
+// Description describes the backend of a map, see Describe
+type Description struct {
+	// Backend is the name of the backend, the one it is registered with for the registered backends
+	Backend      string
+	Capabilities Capabilities
+	Complexity   Complexity
+	// Inner is the description of the map wrapped by this one, if any
+	Inner *Description
+}
+
+// Complexity is the time complexity of the operations of a map of n entries,
+// empty for the operations the map does not support, such as the writes of a read-only map
+type Complexity struct {
+	Load   string
+	Store  string
+	Delete string
+	Range  string
+}
+
+// Describer is implemented by the maps describing themselves,
+// the third-party backends implement it to be described by Describe
+type Describer interface {
+	Describe() Description
+}
+
+// describer is implemented by the in-tree maps
+type describer interface {
+	describe() Description
+}
+
+// Describe describes m, which can be any map of the package, such as a Map, a ReadMap or a snapshot.
+// Generic code can check its capabilities before relying on optional behaviours:
+//
+//	if gomap.Describe(m).Capabilities.Has(gomap.CapOrdered) {
+//		m.(gomap.Iterable[K, V]).Range(scan) // in key order
+//	}
+//
+// A map which does not describe itself is described by its type, with no capabilities.
+func Describe(m any) Description {
+	switch d := m.(type) {
+	case Describer:
+		return d.Describe()
+	case describer:
+		return d.describe()
+	}
+	return Description{Backend: fmt.Sprintf("%T", m)}
+}
+
+// hashComplexity is the complexity of the hash maps
+var hashComplexity = Complexity{Load: constantTime, Store: constantTime, Delete: constantTime, Range: linearTime}
+
+// sortedComplexity is the complexity of the sorted slices, which move the items after an inserted or deleted one
+var sortedComplexity = Complexity{Load: logarithmicTime, Store: linearTime, Delete: linearTime, Range: linearTime}
+
+// readOnlyComplexity returns c without the writes
+func readOnlyComplexity(c Complexity) Complexity {
+	return Complexity{Load: c.Load, Range: c.Range}
+}
+
+// wrapping describes a map wrapping inner, with the capabilities of inner in inherited and caps
+func wrapping(name string, caps Capabilities, inherited Capabilities, inner Description) Description {
+	return Description{
+		Backend:      name,
+		Capabilities: caps | inner.Capabilities&inherited,
+		Complexity:   inner.Complexity,
+		Inner:        &inner,
+	}
+}
@@ -0,0 +1,115 @@
+package gomap
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	durable := openDurable(t, t.TempDir())
+	defer durable.Close()
+	path := filepath.Join(t.TempDir(), "map")
+	if err := WriteMmapFile[int, string](path, NewSortedSliceMap[int, string]()); err != nil {
+		t.Fatal(err)
+	}
+	mmap, err := OpenMmapMap[int, string](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mmap.Close()
+	mvcc := NewMVCCMap[int, string]()
+	snapshot := mvcc.Snapshot()
+	defer snapshot.Release()
+
+	tests := []struct {
+		m       any
+		backend string
+		caps    Capabilities
+		inner   string
+	}{
+		{NewPureMap[int, string](), "PureMap", 0, ""},
+		{NewThreadSafePureMap[int, string](), "ThreadSafePureMap", CapThreadSafe, ""},
+		{NewSyncMap[int, string](), "SyncMap", CapThreadSafe, ""},
+		{NewSortedSliceMap[int, string](), "SortedSliceMap", CapOrdered, ""},
+		{NewThreadSafeSortedSliceMap[int, string](), "ThreadSafeSortedSliceMap", CapOrdered | CapThreadSafe, ""},
+		{NewIntSortedSliceMap[int, string](), "IntSortedSliceMap", CapOrdered | CapFiltered, ""},
+		{NewThreadSafeIntSortedSliceMap[int, string](), "ThreadSafeIntSortedSliceMap", CapOrdered | CapThreadSafe | CapFiltered, ""},
+		{NewLinkedMap[int, string](), "LinkedMap", 0, ""},
+		{NewLRUMap[int, string](10), "LRUMap", CapBounded, ""},
+		{NewThreadSafeLRUMap[int, string](10), "ThreadSafeLRUMap", CapThreadSafe | CapBounded, ""},
+		{mvcc, "MVCCMap", CapThreadSafe, ""},
+		{snapshot, "MVCCSnapshot", CapThreadSafe | CapReadOnly, ""},
+		{NewAdaptiveMap[int, string](), "AdaptiveMap", CapOrdered | CapThreadSafe, "SortedSliceMap"},
+		{NewBoundedMap[int, string](NewIntSortedSliceMap[int, string](), 10, NewLRUPolicy[int]()),
+			"BoundedMap", CapThreadSafe | CapBounded | CapFiltered, "IntSortedSliceMap"},
+		{NewTTLMap[int, string](NewSortedSliceMap[int, string]()), "TTLMap", CapThreadSafe, "SortedSliceMap"},
+		{NewTTLMap[int, string](NewLRUMap[int, string](10)), "TTLMap", CapThreadSafe | CapBounded, "LRUMap"},
+		{NewLoadingMap[int, string](NewThreadSafePureMap[int, string]()), "LoadingMap", CapThreadSafe, "ThreadSafePureMap"},
+		{NewLoadingMap[int, string](NewPureMap[int, string]()), "LoadingMap", 0, "PureMap"},
+		{durable, "DurableMap", CapOrdered | CapThreadSafe | CapPersistent, "ThreadSafeSortedSliceMap"},
+		{mmap, "MmapMap", CapOrdered | CapThreadSafe | CapPersistent | CapReadOnly, ""},
+		{ReadOnly[int, string](NewSortedSliceMap[int, string]()), "ReadOnly", CapOrdered | CapReadOnly, "SortedSliceMap"},
+		{FreezeShared[int, string](NewPureMap[int, string]()), "FrozenPureMap", CapThreadSafe | CapReadOnly, ""},
+		{FreezeShared[int, string](NewSortedSliceMap[int, string]()), "FrozenSortedSliceMap", CapOrdered | CapThreadSafe | CapReadOnly, ""},
+		{Freeze[int, string](NewPureMap[int, string]()), "FrozenMap", CapThreadSafe | CapReadOnly, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			d := Describe(tt.m)
+			if d.Backend != tt.backend || d.Capabilities != tt.caps {
+				t.Errorf("Expected %s %v, but got %s %v", tt.backend, tt.caps, d.Backend, d.Capabilities)
+			}
+			if inner := d.Inner; (inner == nil) != (tt.inner == "") || inner != nil && inner.Backend != tt.inner {
+				t.Errorf("Expected inner %q, but got %+v", tt.inner, inner)
+			}
+			if d.Complexity.Load == "" || d.Complexity.Range == "" {
+				t.Errorf("Expected the complexity of Load and Range, but got %+v", d.Complexity)
+			}
+			if readOnly := d.Complexity.Store == ""; readOnly != d.Capabilities.Has(CapReadOnly) {
+				t.Errorf("Expected the complexity of Store of the maps which are not read-only, but got %+v", d.Complexity)
+			}
+			if _, ok := tt.m.(Iterable[int, string]); d.Capabilities.Has(CapOrdered) && !ok {
+				t.Errorf("Expected an ordered map to be Iterable")
+			}
+			if m, ok := tt.m.(Map[int, string]); ok && d.Capabilities.Has(CapReadOnly) {
+				func() {
+					defer func() {
+						if r := recover(); r != ErrReadOnly {
+							t.Errorf("Expected a read-only map to panic with ErrReadOnly, but got %v", r)
+						}
+					}()
+					m.Store(1, "a")
+				}()
+			}
+		})
+	}
+}
+
+func TestDescribe_Adaptive(t *testing.T) {
+	m := NewAdaptiveMap[int, int](WithAdaptiveWindow(adaptiveSmallLen))
+	for i := 0; i < 4*adaptiveSmallLen; i++ {
+		m.Store(i, i)
+	}
+	d := Describe(m)
+	if d.Inner.Backend != "PureMap" || d.Complexity.Range != sortTime || d.Complexity.Load != constantTime {
+		t.Errorf("Expected the description of the hash map sorted by Range, but got %+v %+v", d, d.Inner)
+	}
+}
+
+// describedMap is a third-party map describing itself
+type describedMap struct {
+	Map[int, int]
+}
+
+func (describedMap) Describe() Description {
+	return Description{Backend: "Described", Capabilities: CapThreadSafe}
+}
+
+func TestDescribe_ThirdParty(t *testing.T) {
+	if d := Describe(describedMap{NewPureMap[int, int]()}); d.Backend != "Described" || d.Capabilities != CapThreadSafe {
+		t.Errorf("Expected the Describer description, but got %+v", d)
+	}
+	if d := Describe(struct{ Map[int, int] }{}); d != (Description{Backend: "struct { gomap.Map[int,int] }"}) {
+		t.Errorf("Expected a description by type, but got %+v", d)
+	}
+}
@@ -0,0 +1,613 @@
+package gomap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Write-ahead log format, a directory holding:
+//
+//	<seq>.snap  a snapshot holding the writes of the segments before seq
+//	<seq>.wal   a segment, a sequence of records:
+//	    size    uvarint, the size of payload
+//	    payload op byte, then the key and the value of a put, encoded with the codecs
+//	    crc     4 bytes, CRC32C of payload
+//
+// seq is 16 hexadecimal digits, so the names sort in order.
+// Only the last segment can end with a torn record, which is dropped on recovery.
+
+const (
+	walSuffix      = ".wal"
+	snapshotSuffix = ".snap"
+	tempSuffix     = ".tmp"
+
+	defaultSyncInterval = time.Second
+	defaultSegmentSize  = 16 << 20
+	defaultCompactSize  = 64 << 20
+)
+
+// ErrClosed is returned by the writes of a DurableMap after Close
+var ErrClosed = errors.New("gomap: map is closed")
+
+// SyncPolicy tells when a DurableMap flushes its log to stable storage
+type SyncPolicy int
+
+const (
+	// SyncAlways flushes every write before applying it, so no acknowledged write is lost
+	SyncAlways SyncPolicy = iota
+	// SyncInterval flushes in the background every WithSyncInterval,
+	// so a crash loses the writes of the last interval at most
+	SyncInterval
+	// SyncNever leaves the flushing to the operating system
+	SyncNever
+)
+
+// WithSyncPolicy sets when a DurableMap flushes its log, SyncAlways by default
+func WithSyncPolicy(p SyncPolicy) Option {
+	return func(o *option) {
+		o.syncPolicy = p
+	}
+}
+
+// WithSyncInterval sets how often the SyncInterval policy flushes the log, every second by default
+func WithSyncInterval(d time.Duration) Option {
+	return func(o *option) {
+		o.syncInterval = d
+	}
+}
+
+// WithSegmentSize sets the size after which a DurableMap starts a new log segment, 16MB by default
+func WithSegmentSize(n int64) Option {
+	return func(o *option) {
+		o.segmentSize = n
+	}
+}
+
+// WithCompactSize makes a DurableMap compact once its log grows by n bytes since the last snapshot,
+// 64MB by default, 0 disables it
+func WithCompactSize(n int64) Option {
+	return func(o *option) {
+		o.compactSize = n
+	}
+}
+
+// DurableMap is a Map persisted by a write-ahead log
+type DurableMap[K comparable, V any] interface {
+	Map[K, V]
+	Iterable[K, V]
+	// Err returns the error which stopped the writes.
+	// A write is logged before being applied, so a failed write is not applied,
+	// and neither are the writes after it.
+	Err() error
+	// Sync flushes the log to stable storage
+	Sync() error
+	// Compact writes a snapshot of the map and removes the log it replaces
+	Compact() error
+	// Close flushes and closes the log
+	Close() error
+}
+
+type walOp byte
+
+const (
+	walPut walOp = iota + 1
+	walDelete
+	walClear
+)
+
+type durableMap[K comparable, V any] struct {
+	Map[K, V]
+	it     Iterable[K, V]
+	dir    string
+	kc     Codec[K]
+	vc     Codec[V]
+	policy SyncPolicy
+
+	segmentSize int64
+	compactSize int64
+
+	mu      sync.Mutex // serializes the writes, so they are applied in the order of the log
+	seg     *os.File   // the last segment, written by append
+	seq     uint64     // sequence number of seg
+	segSize int64
+	logSize int64 // bytes logged since the last snapshot
+	dirty   bool  // seg holds writes not flushed yet
+	payload []byte
+	record  []byte
+	err     error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Durable persists m in dir, which is created if needed.
+// m is replaced by the content recovered from dir: the last snapshot and the log after it.
+// Every Store, Delete and Clear is logged before being applied to m,
+// the log is compacted into a snapshot, written with kc and vc, as it grows.
+// m must be Iterable, and the writes must go through the returned map.
+// thread-safe if m is thread-safe
+func Durable[K comparable, V any](m Map[K, V], dir string, kc Codec[K], vc Codec[V], opts ...Option) (DurableMap[K, V], error) {
+	opt := option{
+		syncInterval: defaultSyncInterval,
+		segmentSize:  defaultSegmentSize,
+		compactSize:  defaultCompactSize,
+	}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	it, ok := m.(Iterable[K, V])
+	if !ok {
+		return nil, ErrNotIterable
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	d := &durableMap[K, V]{
+		Map:         m,
+		it:          it,
+		dir:         dir,
+		kc:          kc,
+		vc:          vc,
+		policy:      opt.syncPolicy,
+		segmentSize: opt.segmentSize,
+		compactSize: opt.compactSize,
+	}
+	if err := d.recover(); err != nil {
+		return nil, err
+	}
+	if d.policy == SyncInterval {
+		d.stop = make(chan struct{})
+		d.done = make(chan struct{})
+		go d.syncer(opt.syncInterval)
+	}
+	return d, nil
+}
+
+func walName(seq uint64, suffix string) string {
+	return fmt.Sprintf("%016x%s", seq, suffix)
+}
+
+// recover loads the last snapshot, replays the segments after it
+// and opens the last one for writing
+func (d *durableMap[K, V]) recover() error {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+	var snapshots, segments []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasSuffix(name, tempSuffix) {
+			// A snapshot whose compaction did not complete
+			if err := os.Remove(filepath.Join(d.dir, name)); err != nil {
+				return err
+			}
+			continue
+		}
+		ext := filepath.Ext(name)
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, ext), 16, 64)
+		if err != nil {
+			continue
+		}
+		switch ext {
+		case snapshotSuffix:
+			snapshots = append(snapshots, seq)
+		case walSuffix:
+			segments = append(segments, seq)
+		}
+	}
+	slices.Sort(snapshots)
+	slices.Sort(segments)
+
+	d.seq = 1
+	d.Map.Clear()
+	if len(snapshots) > 0 {
+		d.seq = snapshots[len(snapshots)-1]
+		if err := d.loadSnapshot(walName(d.seq, snapshotSuffix)); err != nil {
+			return err
+		}
+	}
+	// The log before the snapshot is left by a compaction which did not complete
+	if err := d.removeBefore(d.seq); err != nil {
+		return err
+	}
+
+	segments = slices.DeleteFunc(segments, func(seq uint64) bool { return seq < d.seq })
+	for i, seq := range segments {
+		size, err := d.replay(walName(seq, walSuffix), i == len(segments)-1)
+		if err != nil {
+			return err
+		}
+		d.seq = seq
+		d.segSize = size
+		d.logSize += size
+	}
+
+	flag := os.O_WRONLY | os.O_APPEND
+	if len(segments) == 0 {
+		flag |= os.O_CREATE | os.O_EXCL
+	}
+	d.seg, err = os.OpenFile(filepath.Join(d.dir, walName(d.seq, walSuffix)), flag, 0o644)
+	if err != nil {
+		return err
+	}
+	if len(segments) == 0 {
+		return syncDir(d.dir)
+	}
+	return nil
+}
+
+func (d *durableMap[K, V]) loadSnapshot(name string) error {
+	f, err := os.Open(filepath.Join(d.dir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := ReadSnapshot(bufio.NewReader(f), d.Map, d.kc, d.vc); err != nil {
+		return fmt.Errorf("gomap: snapshot %s: %w", name, err)
+	}
+	return nil
+}
+
+// replay applies the records of a segment and returns the size of the valid ones.
+// A torn or corrupted record ends the last segment, which is truncated to drop it,
+// any other segment must be complete.
+func (d *durableMap[K, V]) replay(name string, last bool) (int64, error) {
+	path := filepath.Join(d.dir, name)
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	cr := &countingReader{r: bufio.NewReader(f)}
+	var valid int64
+	for {
+		err := d.replayRecord(cr)
+		if err == io.EOF && cr.n == valid {
+			return valid, nil
+		}
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			if !last {
+				return 0, fmt.Errorf("gomap: segment %s: %w", name, err)
+			}
+			// The write of the record was interrupted by a crash
+			return valid, os.Truncate(path, valid)
+		}
+		valid = cr.n
+	}
+}
+
+func (d *durableMap[K, V]) replayRecord(cr *countingReader) error {
+	size, err := binary.ReadUvarint(cr)
+	if err != nil {
+		return err
+	}
+	if size == 0 || size > 1<<32 {
+		return ErrBadSnapshot
+	}
+	record := make([]byte, size+4)
+	if _, err := io.ReadFull(cr, record); err != nil {
+		return err
+	}
+	payload := record[:size]
+	if crc32.Checksum(payload, crc32c) != binary.LittleEndian.Uint32(record[size:]) {
+		return ErrChecksum
+	}
+
+	op, payload := walOp(payload[0]), payload[1:]
+	if op == walClear {
+		d.Map.Clear()
+		return nil
+	}
+	key, n, err := d.kc.Decode(payload)
+	if err != nil {
+		return err
+	}
+	switch op {
+	case walPut:
+		val, _, err := d.vc.Decode(payload[n:])
+		if err != nil {
+			return err
+		}
+		d.Map.Store(key, val)
+	case walDelete:
+		d.Map.Delete(key)
+	default:
+		return ErrBadSnapshot
+	}
+	return nil
+}
+
+// removeBefore removes the snapshots and the segments older than seq
+func (d *durableMap[K, V]) removeBefore(seq uint64) error {
+	for _, suffix := range []string{snapshotSuffix, walSuffix} {
+		names, err := filepath.Glob(filepath.Join(d.dir, "*"+suffix))
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			s, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(name), suffix), 16, 64)
+			if err == nil && s < seq {
+				if err := os.Remove(name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
+
+func (d *durableMap[K, V]) syncer(interval time.Duration) {
+	defer close(d.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.mu.Lock()
+			if d.dirty && d.err == nil {
+				d.fail(d.seg.Sync())
+				d.dirty = false
+			}
+			d.mu.Unlock()
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// fail stops the writes if err is not nil, the caller must hold mu
+func (d *durableMap[K, V]) fail(err error) bool {
+	if err != nil && d.err == nil {
+		d.err = err
+	}
+	return d.err != nil
+}
+
+// logLocked appends a record to the log, the caller must hold mu.
+// It returns false if the write must not be applied.
+func (d *durableMap[K, V]) logLocked(op walOp, key K, val V) bool {
+	if d.err != nil {
+		return false
+	}
+
+	var err error
+	d.payload = append(d.payload[:0], byte(op))
+	if op != walClear {
+		if d.payload, err = d.kc.Append(d.payload, key); d.fail(err) {
+			return false
+		}
+	}
+	if op == walPut {
+		if d.payload, err = d.vc.Append(d.payload, val); d.fail(err) {
+			return false
+		}
+	}
+	d.record = binary.AppendUvarint(d.record[:0], uint64(len(d.payload)))
+	d.record = append(d.record, d.payload...)
+	d.record = binary.LittleEndian.AppendUint32(d.record, crc32.Checksum(d.payload, crc32c))
+
+	if _, err := d.seg.Write(d.record); d.fail(err) {
+		return false
+	}
+	if d.policy == SyncAlways {
+		if d.fail(d.seg.Sync()) {
+			return false
+		}
+	} else {
+		d.dirty = true
+	}
+	d.segSize += int64(len(d.record))
+	d.logSize += int64(len(d.record))
+	return true
+}
+
+// maintainLocked starts a new segment or compacts the log once they are due,
+// the caller must hold mu
+func (d *durableMap[K, V]) maintainLocked() {
+	switch {
+	case d.compactSize > 0 && d.logSize >= d.compactSize:
+		d.fail(d.compactLocked())
+	case d.segSize >= d.segmentSize:
+		d.fail(d.rotateLocked())
+	}
+}
+
+// rotateLocked closes the current segment and starts the next one
+func (d *durableMap[K, V]) rotateLocked() error {
+	// Flush even with SyncNever, so only the last segment can be torn
+	if err := d.seg.Sync(); err != nil {
+		return err
+	}
+	if err := d.seg.Close(); err != nil {
+		return err
+	}
+	d.dirty = false
+
+	seg, err := os.OpenFile(filepath.Join(d.dir, walName(d.seq+1, walSuffix)), os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	d.seg = seg
+	d.seq++
+	d.segSize = 0
+	return syncDir(d.dir)
+}
+
+// compactLocked writes a snapshot holding the segments written so far, then removes them
+func (d *durableMap[K, V]) compactLocked() error {
+	if d.segSize > 0 {
+		if err := d.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	name := filepath.Join(d.dir, walName(d.seq, snapshotSuffix))
+	f, err := os.Create(name + tempSuffix)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	_, err = writeSnapshot(w, BackendUnknown, d.it, d.kc, d.vc)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(name+tempSuffix, name)
+	}
+	if err == nil {
+		err = syncDir(d.dir)
+	}
+	if err != nil {
+		os.Remove(name + tempSuffix)
+		return err
+	}
+
+	d.logSize = 0
+	return d.removeBefore(d.seq)
+}
+
+func (d *durableMap[K, V]) Store(key K, val V) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.logLocked(walPut, key, val) {
+		d.Map.Store(key, val)
+		d.maintainLocked()
+	}
+}
+
+func (d *durableMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var zero V
+	if !d.Map.Contain(key) || !d.logLocked(walDelete, key, zero) {
+		return zero, false
+	}
+	defer d.maintainLocked()
+	return d.Map.LoadAndDelete(key)
+}
+
+func (d *durableMap[K, V]) Delete(key K) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var zero V
+	if d.Map.Contain(key) && d.logLocked(walDelete, key, zero) {
+		d.Map.Delete(key)
+		d.maintainLocked()
+	}
+}
+
+func (d *durableMap[K, V]) Clear() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var (
+		key K
+		val V
+	)
+	if d.logLocked(walClear, key, val) {
+		d.Map.Clear()
+		d.maintainLocked()
+	}
+}
+
+func (d *durableMap[K, V]) Len() int {
+	return d.it.Len()
+}
+
+func (d *durableMap[K, V]) Range(f func(key K, val V) bool) {
+	d.it.Range(f)
+}
+
+func (d *durableMap[K, V]) Err() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+func (d *durableMap[K, V]) Sync() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return d.err
+	}
+	if d.fail(d.seg.Sync()) {
+		return d.err
+	}
+	d.dirty = false
+	return nil
+}
+
+func (d *durableMap[K, V]) Compact() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return d.err
+	}
+	d.fail(d.compactLocked())
+	return d.err
+}
+
+// Close is safe to call more than once
+func (d *durableMap[K, V]) Close() error {
+	d.mu.Lock()
+	if d.err == ErrClosed {
+		d.mu.Unlock()
+		return nil
+	}
+	err := d.seg.Sync()
+	if cerr := d.seg.Close(); err == nil {
+		err = cerr
+	}
+	d.err = ErrClosed
+	d.mu.Unlock()
+
+	if d.stop != nil {
+		close(d.stop)
+		<-d.done
+	}
+	return err
+}
+
+func (d *durableMap[K, V]) describe() Description {
+	return wrapping("DurableMap", CapPersistent, CapOrdered|CapThreadSafe|CapFiltered|CapBounded, Describe(d.Map))
+}
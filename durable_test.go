@@ -0,0 +1,161 @@
+package gomap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func openDurable(t *testing.T, dir string, opts ...Option) DurableMap[int, string] {
+	t.Helper()
+	m, err := Durable[int, string](NewThreadSafeSortedSliceMap[int, string](), dir, DefaultCodec[int](), DefaultCodec[string](), opts...)
+	if err != nil {
+		t.Fatalf("Durable: Expected no error, but got %v", err)
+	}
+	return m
+}
+
+func TestDurable_Recover(t *testing.T) {
+	dir := t.TempDir()
+	m := openDurable(t, dir)
+	m.Store(1, "a")
+	m.Store(2, "b")
+	m.Store(3, "c")
+	m.Delete(2)
+	m.Clear()
+	m.Store(4, "d")
+	m.Store(5, "e")
+	if val, ok := m.LoadAndDelete(4); !ok || val != "d" {
+		t.Errorf("LoadAndDelete: Expected d, but got %v", val)
+	}
+	m.Store(5, "f")
+	want := entriesOf[int, string](m)
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close: Expected no error, but got %v", err)
+	}
+
+	m.Store(6, "g")
+	if !errors.Is(m.Err(), ErrClosed) || m.Contain(6) {
+		t.Errorf("Store: Expected ErrClosed and no write after Close, but got %v", m.Err())
+	}
+
+	m = openDurable(t, dir)
+	defer m.Close()
+	if got := entriesOf[int, string](m); !reflect.DeepEqual(got, want) {
+		t.Errorf("Durable: Expected %v after recovery, but got %v", want, got)
+	}
+}
+
+func TestDurable_TornRecord(t *testing.T) {
+	dir := t.TempDir()
+	m := openDurable(t, dir, WithSyncPolicy(SyncNever))
+	m.Store(1, "a")
+	m.Store(2, "b")
+	m.Close()
+
+	// A crash in the middle of the last record
+	segment := filepath.Join(dir, walName(1, walSuffix))
+	info, _ := os.Stat(segment)
+	if err := os.Truncate(segment, info.Size()-2); err != nil {
+		t.Fatal(err)
+	}
+
+	m = openDurable(t, dir)
+	if got := entriesOf[int, string](m); !reflect.DeepEqual(got, []entry[int, string]{{1, "a"}}) {
+		t.Errorf("Durable: Expected the torn record to be dropped, but got %v", got)
+	}
+	m.Store(3, "c")
+	m.Close()
+
+	m = openDurable(t, dir)
+	defer m.Close()
+	if got := entriesOf[int, string](m); !reflect.DeepEqual(got, []entry[int, string]{{1, "a"}, {3, "c"}}) {
+		t.Errorf("Durable: Expected the writes after recovery, but got %v", got)
+	}
+}
+
+func TestDurable_Compact(t *testing.T) {
+	dir := t.TempDir()
+	m := openDurable(t, dir, WithSegmentSize(64), WithCompactSize(1024))
+	for i := 0; i < 500; i++ {
+		m.Store(i%50, "value")
+		if i%3 == 0 {
+			m.Delete((i + 7) % 50)
+		}
+	}
+	if err := m.Err(); err != nil {
+		t.Fatalf("Store: Expected no error, but got %v", err)
+	}
+	want := entriesOf[int, string](m)
+	m.Close()
+
+	snapshots, _ := filepath.Glob(filepath.Join(dir, "*"+snapshotSuffix))
+	segments, _ := filepath.Glob(filepath.Join(dir, "*"+walSuffix))
+	if len(snapshots) != 1 || len(segments) > 1024/64+1 {
+		t.Errorf("Compact: Expected 1 snapshot and a short log, but got %v and %v", snapshots, segments)
+	}
+
+	m = openDurable(t, dir)
+	if got := entriesOf[int, string](m); !reflect.DeepEqual(got, want) {
+		t.Errorf("Durable: Expected %v after recovery, but got %v", want, got)
+	}
+	if err := m.Compact(); err != nil {
+		t.Errorf("Compact: Expected no error, but got %v", err)
+	}
+	m.Close()
+
+	segments, _ = filepath.Glob(filepath.Join(dir, "*"+walSuffix))
+	if len(segments) != 1 {
+		t.Errorf("Compact: Expected a single empty segment, but got %v", segments)
+	}
+	m = openDurable(t, dir)
+	defer m.Close()
+	if got := entriesOf[int, string](m); !reflect.DeepEqual(got, want) {
+		t.Errorf("Durable: Expected %v after compaction, but got %v", want, got)
+	}
+}
+
+func TestDurable_Corruption(t *testing.T) {
+	dir := t.TempDir()
+	m := openDurable(t, dir, WithSegmentSize(16), WithCompactSize(0))
+	for i := 0; i < 10; i++ {
+		m.Store(i, "value")
+	}
+	m.Close()
+
+	// Only the last segment can be torn, a corrupted older one is an error
+	segment := filepath.Join(dir, walName(1, walSuffix))
+	data, _ := os.ReadFile(segment)
+	data[len(data)-1] ^= 0xff
+	os.WriteFile(segment, data, 0o644)
+
+	_, err := Durable[int, string](NewPureMap[int, string](), dir, DefaultCodec[int](), DefaultCodec[string]())
+	if !errors.Is(err, ErrChecksum) {
+		t.Errorf("Durable: Expected ErrChecksum, but got %v", err)
+	}
+}
+
+func TestDurable_SyncInterval(t *testing.T) {
+	dir := t.TempDir()
+	m := openDurable(t, dir, WithSyncPolicy(SyncInterval), WithSyncInterval(time.Millisecond))
+	m.Store(1, "a")
+	time.Sleep(5 * time.Millisecond)
+	if err := m.Sync(); err != nil {
+		t.Errorf("Sync: Expected no error, but got %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Errorf("Close: Expected no error, but got %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Errorf("Close: Expected a second Close to be a no-op, but got %v", err)
+	}
+
+	m = openDurable(t, dir)
+	defer m.Close()
+	if val, _ := m.Load(1); val != "a" {
+		t.Errorf("Durable: Expected a, but got %v", val)
+	}
+}
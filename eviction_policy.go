@@ -0,0 +1,381 @@
+package gomap
+
+import (
+	"container/list"
+	"sync"
+)
+
+// EvictionPolicy decides which key a bounded map evicts.
+// The map tells the policy about its resident keys and asks for a victim when it is full.
+// A policy is used under the lock of its map, so it doesn't need to be thread-safe.
+type EvictionPolicy[K comparable] interface {
+	// Access records a use of a resident key
+	Access(key K)
+	// Insert records a new resident key
+	Insert(key K)
+	// Remove forgets a resident key deleted from the map
+	Remove(key K)
+	// Victim picks a resident key to evict and forgets it,
+	// it returns false if there is no resident key.
+	// The victim can be the key just inserted if the policy rejects it.
+	Victim() (K, bool)
+	// Reset forgets every key
+	Reset()
+}
+
+type boundedMap[K comparable, V any] struct {
+	mu       sync.Mutex
+	backend  Map[K, V]
+	policy   EvictionPolicy[K]
+	capacity int
+	onEvict  func(key K, val V, reason EvictReason)
+	stats    Stats
+
+	costs    map[K]int64 // cost of every entry, also used to count them
+	cost     int64
+	maxCost  int64
+	costFunc func(key K, val V) int64
+}
+
+// NewBoundedMap wraps backend to hold at most capacity entries,
+// policy choosing which entry is evicted when it is full.
+// capacity can be 0 if the map is bounded WithMaxCost only.
+// The writes must go through the returned map, not backend.
+// thread-safe
+func NewBoundedMap[K comparable, V any](backend Map[K, V], capacity int, policy EvictionPolicy[K], opts ...Option) BoundedMap[K, V] {
+	opt := option{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	return &boundedMap[K, V]{
+		backend:  backend,
+		policy:   policy,
+		capacity: capacityOf(capacity, opt),
+		costs:    make(map[K]int64),
+		maxCost:  opt.maxCost,
+		costFunc: costFuncOf[K, V](opt),
+	}
+}
+
+func (m *boundedMap[K, V]) Store(key K, val V) {
+	_ = m.TryStore(key, val)
+}
+
+func (m *boundedMap[K, V]) TryStore(key K, val V) error {
+	cost := m.costFunc(key, val)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.maxCost > 0 && cost > m.maxCost {
+		// The old value must not outlive the write which replaced it
+		if old, ok := m.backend.LoadAndDelete(key); ok {
+			m.stats.Evictions++
+			m.removedLocked(key, old, EvictCapacity)
+		}
+		return ErrCostExceeded
+	}
+
+	old, ok := m.costs[key]
+	m.backend.Store(key, val)
+	m.costs[key] = cost
+	m.cost += cost - old
+	if ok {
+		m.policy.Access(key)
+	} else {
+		m.policy.Insert(key)
+	}
+
+	for len(m.costs) > m.capacity || (m.maxCost > 0 && m.cost > m.maxCost) {
+		victim, ok := m.policy.Victim()
+		if !ok {
+			break
+		}
+		m.evictLocked(victim)
+	}
+	return nil
+}
+
+func (m *boundedMap[K, V]) evictLocked(key K) {
+	val, ok := m.backend.LoadAndDelete(key)
+	if !ok {
+		return
+	}
+	m.cost -= m.costs[key]
+	delete(m.costs, key)
+	m.stats.Evictions++
+	if m.onEvict != nil {
+		m.onEvict(key, val, EvictCapacity)
+	}
+}
+
+func (m *boundedMap[K, V]) Load(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	val, ok := m.backend.Load(key)
+	if !ok {
+		m.stats.Misses++
+		return val, false
+	}
+	m.stats.Hits++
+	m.policy.Access(key)
+	return val, true
+}
+
+func (m *boundedMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	val, ok := m.backend.LoadAndDelete(key)
+	if ok {
+		m.removedLocked(key, val, EvictDelete)
+	}
+	return val, ok
+}
+
+func (m *boundedMap[K, V]) Delete(key K) {
+	m.LoadAndDelete(key)
+}
+
+// Contain doesn't count as a use
+func (m *boundedMap[K, V]) Contain(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.backend.Contain(key)
+}
+
+func (m *boundedMap[K, V]) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.onEvict != nil {
+		// The backend can't be enumerated, so ask the policy for every key
+		for {
+			key, ok := m.policy.Victim()
+			if !ok {
+				break
+			}
+			if val, ok := m.backend.Load(key); ok {
+				m.onEvict(key, val, EvictClear)
+			}
+		}
+	}
+	m.policy.Reset()
+	m.backend.Clear()
+	m.costs = make(map[K]int64)
+	m.cost = 0
+}
+
+func (m *boundedMap[K, V]) removedLocked(key K, val V, reason EvictReason) {
+	m.cost -= m.costs[key]
+	delete(m.costs, key)
+	m.policy.Remove(key)
+	if m.onEvict != nil {
+		m.onEvict(key, val, reason)
+	}
+}
+
+func (m *boundedMap[K, V]) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.costs)
+}
+
+func (m *boundedMap[K, V]) Cap() int {
+	return m.capacity
+}
+
+func (m *boundedMap[K, V]) Cost() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.cost
+}
+
+func (m *boundedMap[K, V]) MaxCost() int64 {
+	return m.maxCost
+}
+
+func (m *boundedMap[K, V]) Headroom() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return headroom(m.maxCost, m.cost)
+}
+
+func (m *boundedMap[K, V]) OnEvict(fn func(key K, val V, reason EvictReason)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.onEvict = fn
+}
+
+func (m *boundedMap[K, V]) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stats
+}
+
+func (m *boundedMap[K, V]) describe() Description {
+	return wrapping("BoundedMap", CapThreadSafe|CapBounded, CapFiltered|CapPersistent, Describe(m.backend))
+}
+
+// keyList is a list of keys which can find the element of a key in O(1)
+type keyList[K comparable] struct {
+	l     list.List
+	elems map[K]*list.Element
+}
+
+func newKeyList[K comparable]() *keyList[K] {
+	return &keyList[K]{elems: make(map[K]*list.Element)}
+}
+
+func (kl *keyList[K]) Len() int {
+	return len(kl.elems)
+}
+
+func (kl *keyList[K]) Contain(key K) bool {
+	_, ok := kl.elems[key]
+	return ok
+}
+
+// PushFront adds key as the newest key
+func (kl *keyList[K]) PushFront(key K) {
+	kl.elems[key] = kl.l.PushFront(key)
+}
+
+func (kl *keyList[K]) MoveToFront(key K) {
+	kl.l.MoveToFront(kl.elems[key])
+}
+
+func (kl *keyList[K]) Remove(key K) bool {
+	e, ok := kl.elems[key]
+	if ok {
+		kl.l.Remove(e)
+		delete(kl.elems, key)
+	}
+	return ok
+}
+
+// Back returns the oldest key
+func (kl *keyList[K]) Back() (K, bool) {
+	e := kl.l.Back()
+	if e == nil {
+		var zero K
+		return zero, false
+	}
+	return e.Value.(K), true
+}
+
+// PopBack removes and returns the oldest key
+func (kl *keyList[K]) PopBack() (K, bool) {
+	key, ok := kl.Back()
+	if ok {
+		kl.Remove(key)
+	}
+	return key, ok
+}
+
+func (kl *keyList[K]) Reset() {
+	kl.l.Init()
+	kl.elems = make(map[K]*list.Element)
+}
+
+type lruPolicy[K comparable] struct {
+	keys *keyList[K]
+}
+
+// NewLRUPolicy evicts the least recently used key
+func NewLRUPolicy[K comparable]() EvictionPolicy[K] {
+	return &lruPolicy[K]{keys: newKeyList[K]()}
+}
+
+func (p *lruPolicy[K]) Access(key K)      { p.keys.MoveToFront(key) }
+func (p *lruPolicy[K]) Insert(key K)      { p.keys.PushFront(key) }
+func (p *lruPolicy[K]) Remove(key K)      { p.keys.Remove(key) }
+func (p *lruPolicy[K]) Victim() (K, bool) { return p.keys.PopBack() }
+func (p *lruPolicy[K]) Reset()            { p.keys.Reset() }
+
+type lfuPolicy[K comparable] struct {
+	freqs   map[K]int
+	buckets map[int]*keyList[K] // keys by frequency, each in recency order
+	minFreq int
+}
+
+// NewLFUPolicy evicts the least frequently used key,
+// the least recently used one among the keys with the same frequency
+func NewLFUPolicy[K comparable]() EvictionPolicy[K] {
+	return &lfuPolicy[K]{
+		freqs:   make(map[K]int),
+		buckets: make(map[int]*keyList[K]),
+	}
+}
+
+func (p *lfuPolicy[K]) bucket(freq int) *keyList[K] {
+	b, ok := p.buckets[freq]
+	if !ok {
+		b = newKeyList[K]()
+		p.buckets[freq] = b
+	}
+	return b
+}
+
+func (p *lfuPolicy[K]) Access(key K) {
+	freq, ok := p.freqs[key]
+	if !ok {
+		return
+	}
+	p.unlink(key, freq)
+	p.freqs[key] = freq + 1
+	p.bucket(freq + 1).PushFront(key)
+}
+
+func (p *lfuPolicy[K]) Insert(key K) {
+	p.freqs[key] = 1
+	p.bucket(1).PushFront(key)
+	p.minFreq = 1
+}
+
+func (p *lfuPolicy[K]) Remove(key K) {
+	if freq, ok := p.freqs[key]; ok {
+		p.unlink(key, freq)
+		delete(p.freqs, key)
+	}
+}
+
+// unlink removes key from its bucket, keeping minFreq up to date
+func (p *lfuPolicy[K]) unlink(key K, freq int) {
+	b := p.buckets[freq]
+	b.Remove(key)
+	if b.Len() == 0 {
+		delete(p.buckets, freq)
+		if p.minFreq == freq {
+			p.minFreq++
+		}
+	}
+}
+
+func (p *lfuPolicy[K]) Victim() (K, bool) {
+	if len(p.freqs) == 0 {
+		var zero K
+		return zero, false
+	}
+	// minFreq can be stale after a Remove of the last key of its bucket
+	for p.buckets[p.minFreq] == nil {
+		p.minFreq++
+	}
+	key, _ := p.buckets[p.minFreq].Back()
+	p.Remove(key)
+	return key, true
+}
+
+func (p *lfuPolicy[K]) Reset() {
+	p.freqs = make(map[K]int)
+	p.buckets = make(map[int]*keyList[K])
+	p.minFreq = 0
+}
@@ -0,0 +1,99 @@
+package gomap
+
+import "testing"
+
+func newPolicies(capacity int) map[string]EvictionPolicy[int] {
+	return map[string]EvictionPolicy[int]{
+		"LRU":       NewLRUPolicy[int](),
+		"LFU":       NewLFUPolicy[int](),
+		"ARC":       NewARCPolicy[int](capacity),
+		"S3-FIFO":   NewS3FIFOPolicy[int](capacity),
+		"W-TinyLFU": NewWTinyLFUPolicy[int](capacity),
+	}
+}
+
+func TestBoundedMap(t *testing.T) {
+	for name, policy := range newPolicies(10) {
+		m := NewBoundedMap(NewPureMap[int, int](), 10, policy)
+		evicted := 0
+		m.OnEvict(func(key, val int, reason EvictReason) {
+			if reason == EvictCapacity {
+				evicted++
+			}
+		})
+
+		for i := 0; i < 100; i++ {
+			m.Store(i, i)
+			m.Load(i)
+			if m.Len() > m.Cap() {
+				t.Errorf("%s: Expected at most %d entries, but got %d", name, m.Cap(), m.Len())
+			}
+		}
+		if m.Len() != 10 {
+			t.Errorf("%s: Expected the map to be full, but got %d entries", name, m.Len())
+		}
+		if stats := m.Stats(); stats.Evictions != 90 || uint64(evicted) != stats.Evictions {
+			t.Errorf("%s: Expected 90 evictions, but got %d and %d notified", name, stats.Evictions, evicted)
+		}
+
+		// Test Delete method and Clear method
+		for i := 0; i < 100; i++ {
+			m.Delete(i)
+		}
+		if m.Len() != 0 {
+			t.Errorf("%s: Expected the map to be empty, but got %d entries", name, m.Len())
+		}
+		m.Store(1, 1)
+		m.Clear()
+		if m.Len() != 0 || m.Contain(1) {
+			t.Errorf("%s: Expected the map to be empty after Clear", name)
+		}
+		if _, ok := policy.Victim(); ok {
+			t.Errorf("%s: Expected the policy to be reset after Clear", name)
+		}
+	}
+}
+
+func TestEvictionPolicy_ScanResistance(t *testing.T) {
+	const capacity = 100
+	hitRates := make(map[string]float64)
+	for name, policy := range newPolicies(capacity) {
+		m := NewBoundedMap(NewPureMap[int, int](), capacity, policy)
+		get := func(key int) {
+			if _, ok := m.Load(key); !ok {
+				m.Store(key, key)
+			}
+		}
+
+		scan := 1000
+		for round := 0; round < 100; round++ {
+			// A hot set fitting in the map, then a scan of new keys flushing an LRU
+			for i := 0; i < 5; i++ {
+				for key := 0; key < capacity/2; key++ {
+					get(key)
+				}
+			}
+			for i := 0; i < capacity; i++ {
+				get(scan)
+				scan++
+			}
+		}
+		hitRates[name] = m.Stats().HitRate()
+	}
+
+	for _, name := range []string{"LFU", "ARC", "S3-FIFO", "W-TinyLFU"} {
+		if hitRates[name] <= hitRates["LRU"] {
+			t.Errorf("%s: Expected a better hit rate than LRU %.2f under scans, but got %.2f",
+				name, hitRates["LRU"], hitRates[name])
+		}
+	}
+}
+
+func TestStats_HitRate(t *testing.T) {
+	if rate := (Stats{}).HitRate(); rate != 0 {
+		t.Errorf("HitRate: Expected 0 without Load, but got %f", rate)
+	}
+	if rate := (Stats{Hits: 3, Misses: 1}).HitRate(); rate != 0.75 {
+		t.Errorf("HitRate: Expected 0.75, but got %f", rate)
+	}
+}
@@ -0,0 +1,372 @@
+package gomap
+
+import (
+	"encoding/binary"
+	"io"
+	"slices"
+)
+
+// FrozenMap is an immutable Map built with a minimal perfect hash function,
+// which sends every key to its own slot of a flat array.
+// Its write methods panic with ErrReadOnly.
+type FrozenMap[K comparable, V any] interface {
+	Map[K, V]
+	Iterable[K, V]
+}
+
+const (
+	// chdBucketSize is the average number of keys per bucket,
+	// larger buckets make a smaller index but a slower build
+	chdBucketSize = 4
+	// chdMaxDisplacement bounds the search of a bucket, after which another seed is tried
+	chdMaxDisplacement = 1 << 16
+	// chdMaxAttempts bounds the seeds tried, after which the keys are indexed by a built-in map
+	chdMaxAttempts = 16
+
+	chdGolden = 0x9e3779b97f4a7c15
+)
+
+// frozenMap is a CHD (compress, hash and displace) table:
+// a key hashes to a bucket, and the displacement of its bucket to its slot.
+type frozenMap[K comparable, V any] struct {
+	seed uint64
+	// disp is the displacement of every bucket,
+	// or -(slot+1) for the buckets of a single key, which are placed directly
+	disp []int32
+	keys []K
+	vals []V
+	// index is the slot of every key when no seed places them all,
+	// for keys which hash the same whatever the seed such as NaN
+	index map[K]int
+}
+
+// Freeze copies the entries of m into a FrozenMap.
+// A lookup costs one hash and one comparison, and the index takes about a byte per key.
+// m must be Iterable, Freeze panics with ErrNotIterable otherwise.
+// thread-safe
+func Freeze[K comparable, V any](m Map[K, V]) FrozenMap[K, V] {
+	it, ok := m.(Iterable[K, V])
+	if !ok {
+		panic(ErrNotIterable)
+	}
+
+	n := it.Len()
+	keys := make([]K, 0, n)
+	vals := make([]V, 0, n)
+	it.Range(func(key K, val V) bool {
+		keys = append(keys, key)
+		vals = append(vals, val)
+		return true
+	})
+	return newFrozenMap(keys, vals)
+}
+
+// newFrozenMap builds the table of distinct keys, reordering keys and vals by slot
+func newFrozenMap[K comparable, V any](keys []K, vals []V) *frozenMap[K, V] {
+	m := &frozenMap[K, V]{}
+	if len(keys) == 0 {
+		return m
+	}
+	for attempt := uint64(1); attempt <= chdMaxAttempts; attempt++ {
+		m.seed = mix64(attempt * chdGolden)
+		if slots, ok := m.build(keys); ok {
+			m.keys = make([]K, len(keys))
+			m.vals = make([]V, len(vals))
+			for i, slot := range slots {
+				m.keys[slot] = keys[i]
+				m.vals[slot] = vals[i]
+			}
+			return m
+		}
+	}
+
+	m.seed, m.disp = 0, nil
+	m.keys, m.vals = slices.Clone(keys), slices.Clone(vals)
+	m.index = make(map[K]int, len(keys))
+	for i, key := range keys {
+		m.index[key] = i
+	}
+	return m
+}
+
+// build places keys with the current seed and returns the slot of every key,
+// it fails if a bucket can't be placed
+func (m *frozenMap[K, V]) build(keys []K) ([]int, bool) {
+	n := len(keys)
+	hashes := make([]uint64, n)
+	buckets := make([][]int, (n+chdBucketSize-1)/chdBucketSize)
+	for i, key := range keys {
+		hashes[i] = hashKey(key, m.seed)
+		b := hashes[i] % uint64(len(buckets))
+		buckets[b] = append(buckets[b], i)
+	}
+	order := make([]int, len(buckets))
+	for b := range order {
+		order[b] = b
+	}
+	// Place the largest buckets first, while most slots are free
+	slices.SortStableFunc(order, func(a, b int) int {
+		return len(buckets[b]) - len(buckets[a])
+	})
+
+	m.disp = make([]int32, len(buckets))
+	slots := make([]int, n)
+	taken := make([]bool, n)
+	free := 0 // every slot before free is taken
+	for _, b := range order {
+		bucket := buckets[b]
+		switch len(bucket) {
+		case 0:
+			continue
+		case 1:
+			for taken[free] {
+				free++
+			}
+			taken[free] = true
+			slots[bucket[0]] = free
+			m.disp[b] = -int32(free) - 1
+			continue
+		}
+
+		placed := false
+		for d := int32(0); d < chdMaxDisplacement && !placed; d++ {
+			placed = true
+			for j, i := range bucket {
+				slot := chdSlot(hashes[i], d, n)
+				if taken[slot] {
+					// Also a collision inside the bucket, whose keys are marked as they are placed
+					for _, prev := range bucket[:j] {
+						taken[slots[prev]] = false
+					}
+					placed = false
+					break
+				}
+				slots[i] = slot
+				taken[slot] = true
+			}
+			if placed {
+				m.disp[b] = d
+			}
+		}
+		if !placed {
+			return nil, false
+		}
+	}
+	return slots, true
+}
+
+func chdSlot(h uint64, d int32, n int) int {
+	return int(mix64(h+uint64(d)*chdGolden) % uint64(n))
+}
+
+// slot returns the slot key would be at
+func (m *frozenMap[K, V]) slot(key K) int {
+	h := hashKey(key, m.seed)
+	d := m.disp[h%uint64(len(m.disp))]
+	if d < 0 {
+		return int(-d - 1)
+	}
+	return chdSlot(h, d, len(m.keys))
+}
+
+func (m *frozenMap[K, V]) Load(key K) (V, bool) {
+	var zero V
+	if len(m.keys) == 0 {
+		return zero, false
+	}
+	if m.index != nil {
+		slot, ok := m.index[key]
+		if !ok {
+			return zero, false
+		}
+		return m.vals[slot], true
+	}
+	slot := m.slot(key)
+	if m.keys[slot] != key {
+		return zero, false
+	}
+	return m.vals[slot], true
+}
+
+func (m *frozenMap[K, V]) Contain(key K) bool {
+	_, ok := m.Load(key)
+	return ok
+}
+
+func (m *frozenMap[K, V]) Store(key K, val V) {
+	panic(ErrReadOnly)
+}
+
+func (m *frozenMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	panic(ErrReadOnly)
+}
+
+func (m *frozenMap[K, V]) Delete(key K) {
+	panic(ErrReadOnly)
+}
+
+func (m *frozenMap[K, V]) Clear() {
+	panic(ErrReadOnly)
+}
+
+func (m *frozenMap[K, V]) Len() int {
+	return len(m.keys)
+}
+
+// Range iterates in the order of the slots
+func (m *frozenMap[K, V]) Range(f func(key K, val V) bool) {
+	for i, key := range m.keys {
+		if !f(key, m.vals[i]) {
+			return
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *frozenMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, building the table of the decoded entries.
+// It initializes the map, so it must not run concurrently with the reads.
+func (m *frozenMap[K, V]) UnmarshalJSON(data []byte) error {
+	decoded := NewPureMap[K, V]()
+	if err := unmarshalJSON[K, V](data, decoded); err != nil {
+		return err
+	}
+	keys := make([]K, 0, decoded.Len())
+	vals := make([]V, 0, decoded.Len())
+	for key, val := range decoded.store {
+		keys = append(keys, key)
+		vals = append(vals, val)
+	}
+	*m = *newFrozenMap(keys, vals)
+	return nil
+}
+
+func (m *frozenMap[K, V]) backendType() BackendType {
+	return BackendFrozenMap
+}
+
+func (m *frozenMap[K, V]) describe() Description {
+	return Description{Backend: "FrozenMap", Capabilities: CapThreadSafe | CapReadOnly, Complexity: readOnlyComplexity(hashComplexity)}
+}
+
+// snapshotIndex encodes the seed and the displacements,
+// the entries of the snapshot being in the order of the slots.
+// A map indexed by a built-in map has no index, and is rebuilt on load.
+func (m *frozenMap[K, V]) snapshotIndex() []byte {
+	if m.index != nil {
+		return nil
+	}
+	index := binary.LittleEndian.AppendUint64(nil, m.seed)
+	index = binary.AppendUvarint(index, uint64(len(m.disp)))
+	for _, d := range m.disp {
+		index = binary.AppendVarint(index, int64(d))
+	}
+	return index
+}
+
+// loadSnapshot replaces the content with a snapshot.
+// The index of a snapshot written by a FrozenMap is checked and reused,
+// the table is built for the snapshots of the other backends.
+func (m *frozenMap[K, V]) loadSnapshot(r io.Reader, kc Codec[K], vc Codec[V]) (int64, error) {
+	var (
+		keys []K
+		vals []V
+	)
+	h, index, n, err := readSnapshot(r, kc, vc, func(key K, val V) {
+		keys = append(keys, key)
+		vals = append(vals, val)
+	})
+	if err != nil {
+		return n, err
+	}
+
+	if h.Backend == BackendFrozenMap && index != nil {
+		loaded := &frozenMap[K, V]{keys: keys, vals: vals}
+		if !loaded.decodeIndex(index) {
+			return n, ErrBadSnapshot
+		}
+		*m = *loaded
+		return n, nil
+	}
+
+	// Keep the last value of a duplicated key, like Store would
+	pos := make(map[K]int, len(keys))
+	distinct := 0
+	for i, key := range keys {
+		if p, ok := pos[key]; ok {
+			vals[p] = vals[i]
+			continue
+		}
+		pos[key] = distinct
+		keys[distinct], vals[distinct] = key, vals[i]
+		distinct++
+	}
+	*m = *newFrozenMap(keys[:distinct], vals[:distinct])
+	return n, nil
+}
+
+// decodeIndex decodes an index and checks that every key is at its slot
+func (m *frozenMap[K, V]) decodeIndex(index []byte) bool {
+	if len(index) < 8 {
+		return false
+	}
+	m.seed = binary.LittleEndian.Uint64(index)
+	index = index[8:]
+	count, n := binary.Uvarint(index)
+	if n <= 0 || count > uint64(len(index)) {
+		return false
+	}
+	index = index[n:]
+	m.disp = make([]int32, count)
+	for i := range m.disp {
+		d, n := binary.Varint(index)
+		if n <= 0 || d < -int64(len(m.keys)) || d >= chdMaxDisplacement {
+			return false
+		}
+		m.disp[i] = int32(d)
+		index = index[n:]
+	}
+	if len(index) != 0 || (len(m.keys) > 0) != (count > 0) {
+		return false
+	}
+	for i, key := range m.keys {
+		if m.slot(key) != i {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (m *frozenMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeIndexedSnapshot[K, V](w, BackendFrozenMap, m, DefaultCodec[K](), DefaultCodec[V](), m.snapshotIndex())
+}
+
+// ReadFrom implements the io.ReaderFrom interface, replacing the content with a snapshot.
+// It initializes the map, so it must not run concurrently with the reads.
+func (m *frozenMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	return m.loadSnapshot(r, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, see WriteTo
+func (m *frozenMap[K, V]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, see ReadFrom
+func (m *frozenMap[K, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m)
+}
+
+// GobEncode implements the gob.GobEncoder interface
+func (m *frozenMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface
+func (m *frozenMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
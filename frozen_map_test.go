@@ -0,0 +1,186 @@
+package gomap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestFrozenMap(t *testing.T) {
+	src := NewPureMap[int, string]()
+	for i := 0; i < 10000; i++ {
+		src.Store(i*7, fmt.Sprint(i))
+	}
+
+	m := Freeze[int, string](src)
+	if m.Len() != 10000 {
+		t.Errorf("Len: Expected 10000, but got %d", m.Len())
+	}
+	for i := 0; i < 10000; i++ {
+		if val, ok := m.Load(i * 7); !ok || val != fmt.Sprint(i) {
+			t.Fatalf("Load: Expected %d, but got %v", i, val)
+		}
+		if m.Contain(i*7 + 1) {
+			t.Fatalf("Contain: Expected key %d to not exist, but it does", i*7+1)
+		}
+	}
+
+	// The source is copied
+	src.Store(1, "one")
+	if m.Contain(1) {
+		t.Errorf("Contain: Expected the frozen map to not see the later writes")
+	}
+
+	defer func() {
+		if r := recover(); r != ErrReadOnly {
+			t.Errorf("Delete: Expected a panic with ErrReadOnly, but got %v", r)
+		}
+	}()
+	m.Delete(7)
+}
+
+func TestFrozenMap_Keys(t *testing.T) {
+	type point struct{ X, Y int }
+
+	empty := Freeze[string, int](NewPureMap[string, int]())
+	if empty.Len() != 0 || empty.Contain("") {
+		t.Errorf("Freeze: Expected an empty map")
+	}
+
+	words := NewLinkedMap[string, int]()
+	for i, word := range []string{"", "a", "b", "ab", "ba", "abc"} {
+		words.Store(word, i)
+	}
+	frozenWords := Freeze[string, int](words)
+	if got := entriesOf[string, int](frozenWords); len(got) != 6 {
+		t.Errorf("Range: Expected 6 entries, but got %v", got)
+	}
+	if val, ok := frozenWords.Load(""); !ok || val != 0 {
+		t.Errorf("Load: Expected 0, but got %v", val)
+	}
+
+	points := NewPureMap[point, bool]()
+	points.Store(point{1, 2}, true)
+	points.Store(point{2, 1}, false)
+	frozenPoints := Freeze[point, bool](points)
+	if val, ok := frozenPoints.Load(point{2, 1}); !ok || val {
+		t.Errorf("Load: Expected false, but got %v", val)
+	}
+	if frozenPoints.Contain(point{1, 1}) {
+		t.Errorf("Contain: Expected {1 1} to not exist, but it does")
+	}
+}
+
+func TestFrozenMap_Collisions(t *testing.T) {
+	// Keys of an interface type differ by their dynamic type
+	mixed := NewPureMap[any, string]()
+	mixed.Store(1, "int")
+	mixed.Store(int64(1), "int64")
+	mixed.Store("1", "string")
+	frozenMixed := Freeze[any, string](mixed)
+	for _, key := range []any{1, int64(1), "1"} {
+		if val, ok := frozenMixed.Load(key); !ok || val != fmt.Sprintf("%T", key) {
+			t.Errorf("Load: Expected %T, but got %v", key, val)
+		}
+	}
+	if frozenMixed.Contain(int32(1)) {
+		t.Errorf("Contain: Expected int32(1) to not exist, but it does")
+	}
+
+	// -0 equals 0
+	floats := NewPureMap[float64, int]()
+	floats.Store(math.Copysign(0, -1), 1)
+	floats.Store(2, 2)
+	if val, ok := Freeze[float64, int](floats).Load(0); !ok || val != 1 {
+		t.Errorf("Load: Expected 1 for 0, but got %v", val)
+	}
+	type point struct{ X, Y float64 }
+	points := NewPureMap[point, int]()
+	points.Store(point{math.Copysign(0, -1), 1}, 1)
+	if val, ok := Freeze[point, int](points).Load(point{0, 1}); !ok || val != 1 {
+		t.Errorf("Load: Expected 1 for {0 1}, but got %v", val)
+	}
+
+	// NaN keys hash the same whatever the seed, so they are indexed by a built-in map
+	nan := NewPureMap[float64, int]()
+	nan.Store(math.NaN(), 1)
+	nan.Store(math.NaN(), 2)
+	nan.Store(3, 3)
+	frozenNaN := Freeze[float64, int](nan)
+	if frozenNaN.Len() != 3 || frozenNaN.Contain(math.NaN()) {
+		t.Errorf("Freeze: Expected 3 entries without a loadable NaN, but got %d", frozenNaN.Len())
+	}
+	if val, ok := frozenNaN.Load(3); !ok || val != 3 {
+		t.Errorf("Load: Expected 3, but got %v", val)
+	}
+	var buf bytes.Buffer
+	if _, err := frozenNaN.(*frozenMap[float64, int]).WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: Expected no error, but got %v", err)
+	}
+	loaded := &frozenMap[float64, int]{}
+	if _, err := loaded.ReadFrom(&buf); err != nil || loaded.Len() != 3 {
+		t.Errorf("ReadFrom: Expected 3 entries, but got %d and %v", loaded.Len(), err)
+	}
+}
+
+func TestFrozenMap_Snapshot(t *testing.T) {
+	src := NewSortedSliceMap[int, string]()
+	for i := 0; i < 1000; i++ {
+		src.Store(i, fmt.Sprint(i))
+	}
+	m := Freeze[int, string](src).(*frozenMap[int, string])
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: Expected no error, but got %v", err)
+	}
+	data := buf.Bytes()
+	header, _ := ReadSnapshotHeader(bytes.NewReader(data))
+	if header.Version != snapshotIndexVersion || header.Backend != BackendFrozenMap || header.Count != 1000 {
+		t.Errorf("WriteTo: Expected an indexed snapshot, but got %+v", header)
+	}
+
+	// Loaded without rebuilding the table
+	loaded := &frozenMap[int, string]{}
+	if _, err := loaded.ReadFrom(bytes.NewReader(data)); err != nil {
+		t.Fatalf("ReadFrom: Expected no error, but got %v", err)
+	}
+	if loaded.seed != m.seed || !reflect.DeepEqual(loaded.disp, m.disp) || !reflect.DeepEqual(loaded.keys, m.keys) {
+		t.Errorf("ReadFrom: Expected the same table")
+	}
+
+	// Any backend reads it, and a frozen map reads any snapshot
+	pure := NewPureMap[int, string]()
+	if err := pure.UnmarshalBinary(data); err != nil || pure.Len() != 1000 {
+		t.Errorf("UnmarshalBinary: Expected 1000 entries, but got %v", err)
+	}
+	rebuilt := &frozenMap[int, string]{}
+	buf.Reset()
+	WriteSnapshot[int, string](&buf, src, FixedIntCodec[int](), StringCodec[string]())
+	if _, err := ReadSnapshot[int, string](&buf, rebuilt, FixedIntCodec[int](), StringCodec[string]()); err != nil {
+		t.Fatalf("ReadSnapshot: Expected no error, but got %v", err)
+	}
+	if val, _ := rebuilt.Load(999); val != "999" || rebuilt.Len() != 1000 {
+		t.Errorf("ReadSnapshot: Expected the table to be built, but got %v", val)
+	}
+
+	// An index which does not match the keys is rejected
+	wrong := &frozenMap[int, string]{keys: m.keys, vals: m.vals}
+	index := m.snapshotIndex()
+	index[0]++
+	if wrong.decodeIndex(index) {
+		t.Errorf("decodeIndex: Expected the index of another seed to be rejected")
+	}
+
+	encoded, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("MarshalJSON: Expected no error, but got %v", err)
+	}
+	decoded := &frozenMap[int, string]{}
+	if err := json.Unmarshal(encoded, decoded); err != nil || decoded.Len() != 1000 {
+		t.Errorf("UnmarshalJSON: Expected 1000 entries, but got %v", err)
+	}
+}
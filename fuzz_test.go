@@ -0,0 +1,177 @@
+package gomap
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type fuzzOpKind byte
+
+const (
+	fuzzStore fuzzOpKind = iota
+	fuzzLoad
+	fuzzDelete
+	fuzzLoadAndDelete
+	fuzzContain
+	fuzzClear
+	fuzzFreeze
+	fuzzOpKinds
+)
+
+// fuzzOp is decoded from 3 bytes: the kind, the key and the value
+type fuzzOp struct {
+	kind fuzzOpKind
+	key  int
+	val  string
+}
+
+func (op fuzzOp) String() string {
+	return fmt.Sprintf("%d(%d, %q)", op.kind, op.key, op.val)
+}
+
+// decodeFuzzOps decodes the operations, the keys being small so they often collide
+func decodeFuzzOps(data []byte) []fuzzOp {
+	ops := make([]fuzzOp, 0, len(data)/3)
+	for i := 0; i+2 < len(data); i += 3 {
+		ops = append(ops, fuzzOp{
+			kind: fuzzOpKind(data[i] % byte(fuzzOpKinds)),
+			key:  int(int8(data[i+1])) / 4,
+			val:  string(rune('a' + data[i+2]%26)),
+		})
+	}
+	return ops
+}
+
+type fuzzResult struct {
+	val string
+	ok  bool
+}
+
+var fuzzBackends = []struct {
+	name    string
+	factory func() Map[int, string]
+	ordered bool
+}{
+	{"PureMap", func() Map[int, string] { return NewPureMap[int, string]() }, false},
+	{"ThreadSafePureMap", func() Map[int, string] { return NewThreadSafePureMap[int, string]() }, false},
+	{"SyncMap", func() Map[int, string] { return NewSyncMap[int, string]() }, false},
+	{"SortedSliceMap", func() Map[int, string] { return NewSortedSliceMap[int, string]() }, true},
+	{"ThreadSafeSortedSliceMap", func() Map[int, string] { return NewThreadSafeSortedSliceMap[int, string]() }, true},
+	{"IntSortedSliceMap", func() Map[int, string] { return NewIntSortedSliceMap[int, string]() }, true},
+	{"ThreadSafeIntSortedSliceMap", func() Map[int, string] { return NewThreadSafeIntSortedSliceMap[int, string]() }, true},
+	{"LinkedMap", func() Map[int, string] { return NewLinkedMap[int, string]() }, false},
+	{"LRUMap", func() Map[int, string] { return NewLRUMap[int, string](1 << 10) }, false},
+	{"ThreadSafeLRUMap", func() Map[int, string] { return NewThreadSafeLRUMap[int, string](1 << 10) }, false},
+	{"MVCCMap", func() Map[int, string] { return NewMVCCMap[int, string]() }, false},
+}
+
+// FuzzBackends applies the same operations to every backend and to a built-in map,
+// and fails on the first result or content which differs.
+// The failing inputs are minimized by the fuzzer and saved in testdata/fuzz/FuzzBackends,
+// where go test replays them as regression seeds.
+func FuzzBackends(f *testing.F) {
+	f.Add([]byte{})
+	// Inserts at the end, at the start and in the middle, then deletes them
+	f.Add([]byte{0, 8, 1, 0, 0, 2, 0, 4, 3, 2, 4, 0, 2, 0, 0, 2, 8, 0, 1, 4, 0})
+	// Writes around a freeze, then a clear
+	f.Add([]byte{0, 1, 1, 0, 2, 2, 6, 0, 0, 0, 1, 3, 2, 2, 0, 5, 0, 0, 0, 3, 4, 6, 0, 0})
+	// Negative keys and overwrites
+	f.Add([]byte{0, 200, 1, 0, 255, 2, 0, 200, 3, 3, 200, 0, 4, 255, 0, 1, 200, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := decodeFuzzOps(data)
+		want, wantFrozen := runFuzzReference(ops)
+
+		for _, b := range fuzzBackends {
+			m := b.factory()
+			var frozen []FrozenMap[int, string]
+			for i, op := range ops {
+				var got fuzzResult
+				switch op.kind {
+				case fuzzStore:
+					m.Store(op.key, op.val)
+				case fuzzLoad:
+					got.val, got.ok = m.Load(op.key)
+				case fuzzDelete:
+					m.Delete(op.key)
+				case fuzzLoadAndDelete:
+					got.val, got.ok = m.LoadAndDelete(op.key)
+				case fuzzContain:
+					got.ok = m.Contain(op.key)
+				case fuzzClear:
+					m.Clear()
+				case fuzzFreeze:
+					frozen = append(frozen, Freeze(m))
+				}
+				if got != want[i] {
+					t.Fatalf("%s: op %d %v: Expected %v, but got %v\nops: %v", b.name, i, op, want[i], got, ops)
+				}
+			}
+
+			expectFuzzContent(t, b.name, m, wantFrozen[len(wantFrozen)-1], b.ordered)
+			for i, fm := range frozen {
+				expectFuzzContent(t, b.name+" frozen", fm, wantFrozen[i], false)
+			}
+		}
+	})
+}
+
+// runFuzzReference applies ops to a built-in map, and returns the result of every op
+// then the content at every freeze followed by the final content
+func runFuzzReference(ops []fuzzOp) ([]fuzzResult, []map[int]string) {
+	ref := make(map[int]string)
+	results := make([]fuzzResult, len(ops))
+	var contents []map[int]string
+	for i, op := range ops {
+		switch op.kind {
+		case fuzzStore:
+			ref[op.key] = op.val
+		case fuzzLoad:
+			results[i].val, results[i].ok = ref[op.key]
+		case fuzzDelete:
+			delete(ref, op.key)
+		case fuzzLoadAndDelete:
+			results[i].val, results[i].ok = ref[op.key]
+			delete(ref, op.key)
+		case fuzzContain:
+			_, results[i].ok = ref[op.key]
+		case fuzzClear:
+			ref = make(map[int]string)
+		case fuzzFreeze:
+			contents = append(contents, copyMap(ref))
+		}
+	}
+	return results, append(contents, ref)
+}
+
+func copyMap(m map[int]string) map[int]string {
+	c := make(map[int]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func expectFuzzContent(t *testing.T, name string, m Map[int, string], want map[int]string, ordered bool) {
+	t.Helper()
+	got := make(map[int]string)
+	var keys []int
+	m.(Iterable[int, string]).Range(func(key int, val string) bool {
+		got[key] = val
+		keys = append(keys, key)
+		return true
+	})
+	if !reflect.DeepEqual(got, want) || len(keys) != len(want) || m.(Iterable[int, string]).Len() != len(want) {
+		t.Fatalf("%s: Expected the content %v, but got %v", name, want, keys)
+	}
+	if ordered && !sort.IntsAreSorted(keys) {
+		t.Fatalf("%s: Expected the keys in order, but got %v", name, keys)
+	}
+	for key, val := range want {
+		if got, ok := m.Load(key); !ok || got != val {
+			t.Fatalf("%s: Load(%d): Expected %q, but got %q", name, key, val, got)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package gomap
 
+import "time"
+
 type Map[K comparable, V any] interface {
 	Store(key K, val V)
 	Load(key K) (V, bool)
@@ -9,10 +11,60 @@ type Map[K comparable, V any] interface {
 	Clear()
 }
 
+// ReadMap is the read part of a Map, which can't change it
+type ReadMap[K comparable, V any] interface {
+	Load(key K) (V, bool)
+	Contain(key K) bool
+	Iterable[K, V]
+}
+
+// WriteMap is the write part of a Map
+type WriteMap[K comparable, V any] interface {
+	Store(key K, val V)
+	LoadAndDelete(key K) (V, bool)
+	Delete(key K)
+	Clear()
+}
+
+// Iterable is implemented by the maps which can enumerate their entries.
+// Range calls f for every entry until f returns false,
+// in key order for the sorted maps.
+// f must not write to a thread-safe map.
+type Iterable[K comparable, V any] interface {
+	Len() int
+	Range(f func(key K, val V) bool)
+}
+
 type Option func(o *option)
 
 type option struct {
-	cap int // 0 mean no cap
+	cap         int // 0 mean no cap
+	shards      int
+	accessOrder bool
+	maxCost     int64 // 0 mean no cost limit
+	costFunc    any   // func(K, V) int64 of the map
+	txnMode     TxnMode
+
+	watchBuffer int
+	overflow    OverflowPolicy
+
+	clock           Clock
+	negativeTTL     time.Duration
+	defaultTTL      time.Duration
+	janitorInterval time.Duration
+
+	refreshAhead         float64
+	staleWhileRevalidate time.Duration
+	refreshWorkers       int
+
+	syncPolicy   SyncPolicy
+	syncInterval time.Duration
+	segmentSize  int64
+	compactSize  int64
+
+	valueCodec any // Codec[V] of the map
+
+	adaptiveWindow int
 }
 
 func WithCap(cap int) Option {
@@ -20,3 +72,10 @@ func WithCap(cap int) Option {
 		o.cap = cap
 	}
 }
+
+// WithTxnMode sets how Txn isolates a transaction on the thread-safe maps
+func WithTxnMode(mode TxnMode) Option {
+	return func(o *option) {
+		o.txnMode = mode
+	}
+}
@@ -0,0 +1,362 @@
+// Package gomaptest provides a conformance suite for the implementations of gomap.Map
+package gomaptest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/lovung/gomap"
+)
+
+// Option configures RunConformance
+type Option func(c *config)
+
+type config struct {
+	ordered    bool
+	threadSafe bool
+}
+
+// Ordered checks that Range iterates in key order
+func Ordered() Option {
+	return func(c *config) {
+		c.ordered = true
+	}
+}
+
+// ThreadSafe checks the map under concurrent writers and readers
+func ThreadSafe() Option {
+	return func(c *config) {
+		c.threadSafe = true
+	}
+}
+
+// RunConformance runs the shared suite of the Map contract as subtests of t.
+// factory must return a new empty map on every call.
+// The iteration tests run if the map is gomap.Iterable.
+func RunConformance(t *testing.T, factory func() gomap.Map[int, string], opts ...Option) {
+	var c config
+	for _, o := range opts {
+		o(&c)
+	}
+
+	t.Run("Empty", func(t *testing.T) { testEmpty(t, factory()) })
+	t.Run("StoreLoad", func(t *testing.T) { testStoreLoad(t, factory()) })
+	t.Run("Overwrite", func(t *testing.T) { testOverwrite(t, factory()) })
+	t.Run("ZeroValues", func(t *testing.T) { testZeroValues(t, factory()) })
+	t.Run("ExtremeKeys", func(t *testing.T) { testExtremeKeys(t, factory()) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, factory()) })
+	t.Run("LoadAndDelete", func(t *testing.T) { testLoadAndDelete(t, factory()) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, factory()) })
+	t.Run("Independent", func(t *testing.T) { testIndependent(t, factory(), factory()) })
+	t.Run("RandomOps", func(t *testing.T) { testRandomOps(t, factory(), c) })
+	t.Run("Range", func(t *testing.T) { testRange(t, factory(), c) })
+	if c.threadSafe {
+		t.Run("Concurrent", func(t *testing.T) { testConcurrent(t, factory()) })
+	}
+}
+
+// expect checks that m holds want, and nothing else if m is iterable
+func expect(t *testing.T, m gomap.Map[int, string], want map[int]string) {
+	t.Helper()
+	for key, val := range want {
+		got, ok := m.Load(key)
+		if !ok || got != val {
+			t.Fatalf("Load(%d): Expected %q, true, but got %q, %v", key, val, got, ok)
+		}
+		if !m.Contain(key) {
+			t.Fatalf("Contain(%d): Expected true, but got false", key)
+		}
+	}
+	it, ok := m.(gomap.Iterable[int, string])
+	if !ok {
+		return
+	}
+	if it.Len() != len(want) {
+		t.Fatalf("Len: Expected %d, but got %d", len(want), it.Len())
+	}
+	got := make(map[int]string)
+	it.Range(func(key int, val string) bool {
+		if _, dup := got[key]; dup {
+			t.Fatalf("Range: Expected every key once, but got %d twice", key)
+		}
+		got[key] = val
+		return true
+	})
+	for key, val := range got {
+		if want[key] != val {
+			t.Fatalf("Range: Expected %d to not be there or be %q, but got %q", key, want[key], val)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Range: Expected %d entries, but got %d", len(want), len(got))
+	}
+}
+
+// expectMissing checks that key is not in m
+func expectMissing(t *testing.T, m gomap.Map[int, string], key int) {
+	t.Helper()
+	if val, ok := m.Load(key); ok || val != "" {
+		t.Fatalf("Load(%d): Expected \"\", false, but got %q, %v", key, val, ok)
+	}
+	if m.Contain(key) {
+		t.Fatalf("Contain(%d): Expected false, but got true", key)
+	}
+}
+
+func testEmpty(t *testing.T, m gomap.Map[int, string]) {
+	for _, key := range []int{0, 1, -1, math.MaxInt} {
+		expectMissing(t, m, key)
+		if val, ok := m.LoadAndDelete(key); ok || val != "" {
+			t.Fatalf("LoadAndDelete(%d): Expected \"\", false, but got %q, %v", key, val, ok)
+		}
+	}
+	m.Delete(1)
+	m.Clear()
+	expect(t, m, map[int]string{})
+}
+
+func testStoreLoad(t *testing.T, m gomap.Map[int, string]) {
+	want := make(map[int]string)
+	for _, key := range []int{5, 3, 8, 1, 4, 7, 9, 2, 6} {
+		m.Store(key, fmt.Sprint("v", key))
+		want[key] = fmt.Sprint("v", key)
+		expect(t, m, want)
+	}
+	expectMissing(t, m, 10)
+	expectMissing(t, m, -5)
+}
+
+func testOverwrite(t *testing.T, m gomap.Map[int, string]) {
+	m.Store(1, "a")
+	m.Store(2, "b")
+	m.Store(1, "c")
+	expect(t, m, map[int]string{1: "c", 2: "b"})
+	m.Store(1, "")
+	expect(t, m, map[int]string{1: "", 2: "b"})
+}
+
+func testZeroValues(t *testing.T, m gomap.Map[int, string]) {
+	// The zero key and the zero value are entries like any other
+	expectMissing(t, m, 0)
+	m.Store(0, "")
+	expect(t, m, map[int]string{0: ""})
+	m.Store(0, "zero")
+	m.Store(1, "")
+	expect(t, m, map[int]string{0: "zero", 1: ""})
+	if val, ok := m.LoadAndDelete(0); !ok || val != "zero" {
+		t.Fatalf("LoadAndDelete(0): Expected \"zero\", true, but got %q, %v", val, ok)
+	}
+	expectMissing(t, m, 0)
+	expect(t, m, map[int]string{1: ""})
+}
+
+func testExtremeKeys(t *testing.T, m gomap.Map[int, string]) {
+	want := map[int]string{math.MinInt: "min", math.MaxInt: "max", -1: "minus one", 1 << 40: "big"}
+	for key, val := range want {
+		m.Store(key, val)
+	}
+	expect(t, m, want)
+	for _, key := range []int{math.MinInt + 1, math.MaxInt - 1, -2, 1<<40 + 1} {
+		expectMissing(t, m, key)
+	}
+}
+
+func testDelete(t *testing.T, m gomap.Map[int, string]) {
+	want := make(map[int]string)
+	for key := 0; key < 10; key++ {
+		m.Store(key, fmt.Sprint(key))
+		want[key] = fmt.Sprint(key)
+	}
+	// The first, the last and one in the middle
+	for _, key := range []int{0, 9, 5} {
+		m.Delete(key)
+		delete(want, key)
+		expectMissing(t, m, key)
+		expect(t, m, want)
+	}
+	m.Delete(5)
+	m.Delete(100)
+	expect(t, m, want)
+
+	m.Store(5, "again")
+	want[5] = "again"
+	expect(t, m, want)
+}
+
+func testLoadAndDelete(t *testing.T, m gomap.Map[int, string]) {
+	m.Store(1, "a")
+	m.Store(2, "b")
+	if val, ok := m.LoadAndDelete(1); !ok || val != "a" {
+		t.Fatalf("LoadAndDelete(1): Expected \"a\", true, but got %q, %v", val, ok)
+	}
+	if val, ok := m.LoadAndDelete(1); ok || val != "" {
+		t.Fatalf("LoadAndDelete(1): Expected \"\", false the second time, but got %q, %v", val, ok)
+	}
+	expect(t, m, map[int]string{2: "b"})
+}
+
+func testClear(t *testing.T, m gomap.Map[int, string]) {
+	for key := -50; key < 50; key++ {
+		m.Store(key, fmt.Sprint(key))
+	}
+	m.Clear()
+	expect(t, m, map[int]string{})
+	for key := -50; key < 50; key++ {
+		expectMissing(t, m, key)
+	}
+
+	// Nothing of the content before Clear comes back
+	m.Store(1000, "new")
+	m.Store(-7, "new")
+	expect(t, m, map[int]string{1000: "new", -7: "new"})
+	for _, key := range []int{1, 7, 8, 24, 32, 49} {
+		expectMissing(t, m, key)
+	}
+
+	m.Clear()
+	m.Clear()
+	expect(t, m, map[int]string{})
+}
+
+func testIndependent(t *testing.T, a, b gomap.Map[int, string]) {
+	a.Store(1, "a")
+	b.Store(2, "b")
+	expect(t, a, map[int]string{1: "a"})
+	expect(t, b, map[int]string{2: "b"})
+	a.Clear()
+	expect(t, b, map[int]string{2: "b"})
+}
+
+func testRandomOps(t *testing.T, m gomap.Map[int, string], c config) {
+	r := rand.New(rand.NewSource(1))
+	want := make(map[int]string)
+	for i := 0; i < 5000; i++ {
+		key := r.Intn(64) - 32
+		switch op := r.Intn(100); {
+		case op < 50:
+			val := fmt.Sprint(i)
+			m.Store(key, val)
+			want[key] = val
+		case op < 70:
+			m.Delete(key)
+			delete(want, key)
+		case op < 85:
+			val, ok := m.LoadAndDelete(key)
+			wantVal, wantOK := want[key]
+			if val != wantVal || ok != wantOK {
+				t.Fatalf("op %d: LoadAndDelete(%d): Expected %q, %v, but got %q, %v", i, key, wantVal, wantOK, val, ok)
+			}
+			delete(want, key)
+		case op < 99:
+			val, ok := m.Load(key)
+			wantVal, wantOK := want[key]
+			if val != wantVal || ok != wantOK {
+				t.Fatalf("op %d: Load(%d): Expected %q, %v, but got %q, %v", i, key, wantVal, wantOK, val, ok)
+			}
+		default:
+			m.Clear()
+			want = make(map[int]string)
+		}
+		if i%100 == 0 {
+			expect(t, m, want)
+			expectOrder(t, m, c)
+		}
+	}
+	expect(t, m, want)
+	expectOrder(t, m, c)
+}
+
+// expectOrder checks that an ordered map iterates in key order
+func expectOrder(t *testing.T, m gomap.Map[int, string], c config) {
+	t.Helper()
+	it, ok := m.(gomap.Iterable[int, string])
+	if !ok || !c.ordered {
+		return
+	}
+	var keys []int
+	it.Range(func(key int, _ string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if !sort.SliceIsSorted(keys, func(i, j int) bool { return keys[i] < keys[j] }) {
+		t.Fatalf("Range: Expected the keys in order, but got %v", keys)
+	}
+}
+
+func testRange(t *testing.T, m gomap.Map[int, string], c config) {
+	it, ok := m.(gomap.Iterable[int, string])
+	if !ok {
+		t.Skip("the map is not Iterable")
+	}
+
+	calls := 0
+	it.Range(func(int, string) bool {
+		calls++
+		return true
+	})
+	if calls != 0 {
+		t.Fatalf("Range: Expected no call on an empty map, but got %d", calls)
+	}
+
+	for _, key := range []int{3, -1, 7, 0, 5, math.MinInt, 2} {
+		m.Store(key, fmt.Sprint(key))
+	}
+	expectOrder(t, m, c)
+
+	// Range stops once f returns false
+	calls = 0
+	var first int
+	it.Range(func(key int, _ string) bool {
+		calls++
+		first = key
+		return false
+	})
+	if calls != 1 {
+		t.Fatalf("Range: Expected 1 call, but got %d", calls)
+	}
+	if c.ordered && first != math.MinInt {
+		t.Fatalf("Range: Expected the smallest key first, but got %d", first)
+	}
+}
+
+func testConcurrent(t *testing.T, m gomap.Map[int, string]) {
+	const (
+		goroutines = 8
+		keys       = 200
+	)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			// Every goroutine owns the keys equal to g modulo goroutines
+			for i := 0; i < keys; i++ {
+				key := i*goroutines + g
+				m.Store(key, fmt.Sprint(key))
+				if val, ok := m.Load(key); !ok || val != fmt.Sprint(key) {
+					t.Errorf("Load(%d): Expected %q, true, but got %q, %v", key, fmt.Sprint(key), val, ok)
+					return
+				}
+				if i%2 == 0 {
+					if val, ok := m.LoadAndDelete(key); !ok || val != fmt.Sprint(key) {
+						t.Errorf("LoadAndDelete(%d): Expected %q, true, but got %q, %v", key, fmt.Sprint(key), val, ok)
+						return
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	want := make(map[int]string)
+	for key := 0; key < keys*goroutines; key++ {
+		if (key/goroutines)%2 == 1 {
+			want[key] = fmt.Sprint(key)
+		}
+	}
+	expect(t, m, want)
+}
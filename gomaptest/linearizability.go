@@ -0,0 +1,207 @@
+package gomaptest
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lovung/gomap"
+)
+
+// OpKind is the method called by an Operation
+type OpKind int
+
+const (
+	OpStore OpKind = iota
+	OpLoad
+	OpDelete
+	OpLoadAndDelete
+	OpContain
+)
+
+func (k OpKind) String() string {
+	return [...]string{"Store", "Load", "Delete", "LoadAndDelete", "Contain"}[k]
+}
+
+// Operation is a call recorded in a concurrent history
+type Operation struct {
+	Kind OpKind
+	Key  int
+	Val  string // the value of Store, or the value returned
+	OK   bool   // the bool returned
+	// Call and Return are the times of the invocation and of the response,
+	// an operation happened before another if it returned before the other was called
+	Call, Return int64
+}
+
+func (op Operation) String() string {
+	switch op.Kind {
+	case OpStore:
+		return fmt.Sprintf("[%d,%d] Store(%d, %q)", op.Call, op.Return, op.Key, op.Val)
+	case OpDelete:
+		return fmt.Sprintf("[%d,%d] Delete(%d)", op.Call, op.Return, op.Key)
+	case OpContain:
+		return fmt.Sprintf("[%d,%d] Contain(%d) = %v", op.Call, op.Return, op.Key, op.OK)
+	}
+	return fmt.Sprintf("[%d,%d] %v(%d) = %q, %v", op.Call, op.Return, op.Kind, op.Key, op.Val, op.OK)
+}
+
+// keyState is the state of a key in the sequential model of a map
+type keyState struct {
+	val     string
+	present bool
+}
+
+// step applies op to the state of its key, and tells if its results are the ones of the model
+func step(s keyState, op Operation) (keyState, bool) {
+	switch op.Kind {
+	case OpStore:
+		return keyState{val: op.Val, present: true}, true
+	case OpLoad:
+		return s, op.OK == s.present && op.Val == s.val
+	case OpDelete:
+		return keyState{}, true
+	case OpLoadAndDelete:
+		return keyState{}, op.OK == s.present && op.Val == s.val
+	default: // OpContain
+		return s, op.OK == s.present
+	}
+}
+
+// Linearizable tells if the history of operations on an initially empty map is linearizable:
+// if there is an order of the operations, consistent with their times,
+// in which a sequential map would return the same results.
+// The keys are independent, so the history of every key is checked on its own.
+func Linearizable(history []Operation) bool {
+	_, ok := nonLinearizableKey(history)
+	return !ok
+}
+
+// nonLinearizableKey returns the operations of a key whose history is not linearizable
+func nonLinearizableKey(history []Operation) ([]Operation, bool) {
+	byKey := make(map[int][]Operation)
+	for _, op := range history {
+		byKey[op.Key] = append(byKey[op.Key], op)
+	}
+	for _, ops := range byKey {
+		if !linearizableKey(ops) {
+			sort.Slice(ops, func(i, j int) bool { return ops[i].Call < ops[j].Call })
+			return ops, true
+		}
+	}
+	return nil, false
+}
+
+// linearizableKey is the Wing–Gong search, memoizing the visited states like Lowe's variant:
+// the next operation of the order can be any pending one called before all the pending ones returned
+func linearizableKey(ops []Operation) bool {
+	done := make([]uint64, (len(ops)+63)/64)
+	visited := make(map[string]bool)
+
+	var search func(s keyState, remaining int) bool
+	search = func(s keyState, remaining int) bool {
+		if remaining == 0 {
+			return true
+		}
+		firstReturn := int64(1<<63 - 1)
+		for i, op := range ops {
+			if done[i/64]&(1<<(i%64)) == 0 && op.Return < firstReturn {
+				firstReturn = op.Return
+			}
+		}
+		for i, op := range ops {
+			if done[i/64]&(1<<(i%64)) != 0 || op.Call > firstReturn {
+				continue
+			}
+			next, ok := step(s, op)
+			if !ok {
+				continue
+			}
+			done[i/64] |= 1 << (i % 64)
+			key := fmt.Sprint(done, next)
+			if !visited[key] {
+				visited[key] = true
+				if search(next, remaining-1) {
+					return true
+				}
+			}
+			done[i/64] &^= 1 << (i % 64)
+		}
+		return false
+	}
+	return search(keyState{}, len(ops))
+}
+
+// RunLinearizability runs rounds of concurrent operations on few keys against maps of factory,
+// records their histories and fails if one is not linearizable.
+// factory must return a new empty thread-safe map on every call.
+// GOMAXPROCS is raised to the number of goroutines meanwhile, so the operations overlap even on a single CPU.
+func RunLinearizability(t *testing.T, factory func() gomap.Map[int, string]) {
+	t.Helper()
+	const (
+		rounds     = 500
+		goroutines = 8
+		opsPerG    = 25
+		keys       = 2
+	)
+	if runtime.GOMAXPROCS(0) < goroutines {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(goroutines))
+	}
+
+	for round := 0; round < rounds; round++ {
+		m := factory()
+		var (
+			clock   atomic.Int64
+			start   sync.WaitGroup
+			wg      sync.WaitGroup
+			history = make([][]Operation, goroutines)
+		)
+		start.Add(1)
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				r := rand.New(rand.NewSource(int64(round*goroutines + g)))
+				start.Wait()
+				for i := 0; i < opsPerG; i++ {
+					op := Operation{Kind: OpKind(r.Intn(5)), Key: r.Intn(keys)}
+					op.Call = clock.Add(1)
+					switch op.Kind {
+					case OpStore:
+						op.Val = fmt.Sprintf("%d.%d", g, i)
+						m.Store(op.Key, op.Val)
+					case OpLoad:
+						op.Val, op.OK = m.Load(op.Key)
+					case OpDelete:
+						m.Delete(op.Key)
+					case OpLoadAndDelete:
+						op.Val, op.OK = m.LoadAndDelete(op.Key)
+					case OpContain:
+						op.OK = m.Contain(op.Key)
+					}
+					op.Return = clock.Add(1)
+					history[g] = append(history[g], op)
+				}
+			}(g)
+		}
+		start.Done()
+		wg.Wait()
+
+		var all []Operation
+		for _, ops := range history {
+			all = append(all, ops...)
+		}
+		if ops, ok := nonLinearizableKey(all); ok {
+			lines := make([]string, len(ops))
+			for i, op := range ops {
+				lines[i] = op.String()
+			}
+			t.Fatalf("round %d: Expected a linearizable history, but got:\n%s", round, strings.Join(lines, "\n"))
+		}
+	}
+}
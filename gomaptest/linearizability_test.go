@@ -0,0 +1,73 @@
+package gomaptest
+
+import "testing"
+
+func TestLinearizable(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []Operation
+		want    bool
+	}{
+		{
+			name: "sequential",
+			history: []Operation{
+				{Kind: OpStore, Key: 1, Val: "a", Call: 1, Return: 2},
+				{Kind: OpLoad, Key: 1, Val: "a", OK: true, Call: 3, Return: 4},
+				{Kind: OpLoadAndDelete, Key: 1, Val: "a", OK: true, Call: 5, Return: 6},
+				{Kind: OpContain, Key: 1, Call: 7, Return: 8},
+			},
+			want: true,
+		},
+		{
+			name: "stale load",
+			history: []Operation{
+				{Kind: OpStore, Key: 1, Val: "a", Call: 1, Return: 2},
+				{Kind: OpLoad, Key: 1, Call: 3, Return: 4},
+			},
+			want: false,
+		},
+		{
+			name: "load concurrent with store",
+			history: []Operation{
+				{Kind: OpStore, Key: 1, Val: "a", Call: 1, Return: 4},
+				{Kind: OpLoad, Key: 1, Call: 2, Return: 3},
+				{Kind: OpLoad, Key: 1, Val: "a", OK: true, Call: 2, Return: 5},
+			},
+			want: true,
+		},
+		{
+			name: "reordered loads",
+			history: []Operation{
+				{Kind: OpStore, Key: 1, Val: "a", Call: 1, Return: 10},
+				{Kind: OpLoad, Key: 1, Val: "a", OK: true, Call: 2, Return: 3},
+				{Kind: OpLoad, Key: 1, Call: 4, Return: 5},
+			},
+			want: false,
+		},
+		{
+			name: "double LoadAndDelete",
+			history: []Operation{
+				{Kind: OpStore, Key: 1, Val: "a", Call: 1, Return: 2},
+				{Kind: OpLoadAndDelete, Key: 1, Val: "a", OK: true, Call: 3, Return: 6},
+				{Kind: OpLoadAndDelete, Key: 1, Val: "a", OK: true, Call: 4, Return: 5},
+			},
+			want: false,
+		},
+		{
+			name: "independent keys",
+			history: []Operation{
+				{Kind: OpStore, Key: 1, Val: "a", Call: 1, Return: 2},
+				{Kind: OpLoad, Key: 2, Call: 3, Return: 4},
+				{Kind: OpLoad, Key: 1, Val: "a", OK: true, Call: 5, Return: 6},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Linearizable(tt.history); got != tt.want {
+				t.Errorf("Expected %v, but got %v", tt.want, got)
+			}
+		})
+	}
+}
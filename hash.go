@@ -0,0 +1,115 @@
+package gomap
+
+import (
+	"math"
+	"reflect"
+)
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// hashKey hashes key with seed, so that equal keys have the same hash.
+// The result only depends on the value of key, and on its dynamic type if K is an interface,
+// so it is stable across processes for the keys without pointers.
+func hashKey[K comparable](key K, seed uint64) uint64 {
+	// The zero value of an interface type is the only one converting to a nil any,
+	// the dynamic type of its keys must be hashed: any(1) and any(int64(1)) are different keys
+	if any(*new(K)) == nil {
+		return hashValue(reflect.ValueOf(&key).Elem(), seed)
+	}
+
+	switch k := any(key).(type) {
+	case string:
+		return hashString(k, seed)
+	case int:
+		return mix64(uint64(k) ^ seed)
+	case int64:
+		return mix64(uint64(k) ^ seed)
+	case int32:
+		return mix64(uint64(k) ^ seed)
+	case uint:
+		return mix64(uint64(k) ^ seed)
+	case uint64:
+		return mix64(k ^ seed)
+	case uint32:
+		return mix64(uint64(k) ^ seed)
+	}
+	return hashValue(reflect.ValueOf(key), seed)
+}
+
+// hashValue hashes the value of a comparable type, following how == compares it
+func hashValue(v reflect.Value, seed uint64) uint64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return mix64(uint64(v.Int()) ^ seed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return mix64(v.Uint() ^ seed)
+	case reflect.Float32, reflect.Float64:
+		return hashFloat(v.Float(), seed)
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return hashFloat(imag(c), hashFloat(real(c), seed))
+	case reflect.String:
+		return hashString(v.String(), seed)
+	case reflect.Bool:
+		if v.Bool() {
+			return mix64(1 ^ seed)
+		}
+		return mix64(seed)
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		return mix64(uint64(v.Pointer()) ^ seed)
+	case reflect.Interface:
+		if v.IsNil() {
+			return mix64(seed)
+		}
+		return hashValue(v.Elem(), hashString(v.Elem().Type().String(), seed))
+	case reflect.Array:
+		h := mix64(seed)
+		for i := 0; i < v.Len(); i++ {
+			h = hashValue(v.Index(i), h)
+		}
+		return h
+	case reflect.Struct:
+		h := mix64(seed)
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			// == ignores the blank fields
+			if t.Field(i).Name != "_" {
+				h = hashValue(v.Field(i), h)
+			}
+		}
+		return h
+	}
+	// The other kinds are not comparable, a map of them panics before hashing
+	return mix64(seed)
+}
+
+// hashFloat hashes the bits of f, with -0 hashed as 0 which it equals
+func hashFloat(f float64, seed uint64) uint64 {
+	if f == 0 {
+		f = 0
+	}
+	return mix64(math.Float64bits(f) ^ seed)
+}
+
+// hashString is FNV-1a seeded and finalized with mix64
+func hashString(s string, seed uint64) uint64 {
+	h := uint64(fnvOffset) ^ seed
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime
+	}
+	return mix64(h)
+}
+
+// mix64 is the finalizer of SplitMix64
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
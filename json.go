@@ -0,0 +1,169 @@
+package gomap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// JSON encoding of the maps.
+// A map with string keys is encoded as an object, any other map as an array of [key, value] pairs.
+// The entries are written in the order of Range, so the sorted maps stay sorted
+// and the LinkedMap keeps its order when it is decoded.
+
+// ErrNotIterable is returned when a map can't enumerate its entries
+var ErrNotIterable = errors.New("gomap: map is not iterable")
+
+// isStringKey reports whether K is encoded as a JSON object key
+func isStringKey[K comparable]() bool {
+	return reflect.TypeOf((*K)(nil)).Elem().Kind() == reflect.String
+}
+
+// EncodeJSON writes the entries of m to w as they are ranged, without buffering the whole map
+func EncodeJSON[K comparable, V any](w io.Writer, m Map[K, V]) error {
+	it, ok := m.(Iterable[K, V])
+	if !ok {
+		return ErrNotIterable
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := encodeJSON(bw, it); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func encodeJSON[K comparable, V any](w *bufio.Writer, it Iterable[K, V]) error {
+	object := isStringKey[K]()
+	start, end := byte('['), byte(']')
+	if object {
+		start, end = '{', '}'
+	}
+
+	var err error
+	first := true
+	_ = w.WriteByte(start)
+	it.Range(func(key K, val V) bool {
+		if !first {
+			_ = w.WriteByte(',')
+		}
+		first = false
+
+		var k, v []byte
+		if k, err = json.Marshal(key); err != nil {
+			return false
+		}
+		if v, err = json.Marshal(val); err != nil {
+			return false
+		}
+		if object {
+			_, _ = w.Write(k)
+			_ = w.WriteByte(':')
+			_, err = w.Write(v)
+		} else {
+			_ = w.WriteByte('[')
+			_, _ = w.Write(k)
+			_ = w.WriteByte(',')
+			_, _ = w.Write(v)
+			err = w.WriteByte(']')
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return w.WriteByte(end)
+}
+
+// DecodeJSON reads entries from r and stores them in m in their order,
+// without clearing m first
+func DecodeJSON[K comparable, V any](r io.Reader, m Map[K, V]) error {
+	return decodeJSON(json.NewDecoder(r), m.Store)
+}
+
+func decodeJSON[K comparable, V any](dec *json.Decoder, store func(key K, val V)) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case nil:
+		return nil
+	case json.Delim('{'):
+		if !isStringKey[K]() {
+			return fmt.Errorf("gomap: can't decode a JSON object into a map of %T keys", *new(K))
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			var key K
+			reflect.ValueOf(&key).Elem().SetString(tok.(string))
+			var val V
+			if err := dec.Decode(&val); err != nil {
+				return err
+			}
+			store(key, val)
+		}
+	case json.Delim('['):
+		for dec.More() {
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+			var key K
+			var val V
+			if err := dec.Decode(&key); err != nil {
+				return err
+			}
+			if err := dec.Decode(&val); err != nil {
+				return err
+			}
+			if err := expectDelim(dec, ']'); err != nil {
+				return err
+			}
+			store(key, val)
+		}
+	default:
+		return fmt.Errorf("gomap: can't decode %v into a map", tok)
+	}
+
+	// Consume the closing delimiter
+	_, err = dec.Token()
+	return err
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("gomap: expected %v in JSON pair, got %v", delim, tok)
+	}
+	return nil
+}
+
+// marshalJSON implements json.Marshaler for an iterable map
+func marshalJSON[K comparable, V any](it Iterable[K, V]) ([]byte, error) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	if err := encodeJSON(w, it); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalJSON implements json.Unmarshaler, replacing the content of m
+func unmarshalJSON[K comparable, V any](data []byte, m Map[K, V]) error {
+	m.Clear()
+	return decodeJSON(json.NewDecoder(bytes.NewReader(data)), m.Store)
+}
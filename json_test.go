@@ -0,0 +1,173 @@
+package gomap
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	type config struct {
+		Names  Map[string, int] `json:"names"`
+		Scores Map[int, string] `json:"scores"`
+	}
+
+	c := config{
+		Names:  NewSortedSliceMap[string, int](),
+		Scores: NewThreadSafeIntSortedSliceMap[int, string](),
+	}
+	c.Names.Store("b", 2)
+	c.Names.Store("a", 1)
+	c.Scores.Store(2, "two")
+	c.Scores.Store(1, "one")
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal: Expected no error, but got %v", err)
+	}
+	expected := `{"names":{"a":1,"b":2},"scores":[[1,"one"],[2,"two"]]}`
+	if string(data) != expected {
+		t.Errorf("Marshal: Expected %s, but got %s", expected, data)
+	}
+
+	decoded := config{
+		Names:  NewPureMap[string, int](),
+		Scores: NewSyncMap[int, string](),
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal: Expected no error, but got %v", err)
+	}
+	if val, _ := decoded.Names.Load("b"); val != 2 {
+		t.Errorf("Unmarshal: Expected value 2, but got %d", val)
+	}
+	if val, _ := decoded.Scores.Load(1); val != "one" {
+		t.Errorf("Unmarshal: Expected value 'one', but got '%s'", val)
+	}
+}
+
+func TestMarshalJSON_Backends(t *testing.T) {
+	factories := map[string]func() Map[string, int]{
+		"PureMap":                  func() Map[string, int] { return NewPureMap[string, int]() },
+		"ThreadSafePureMap":        func() Map[string, int] { return NewThreadSafePureMap[string, int]() },
+		"SyncMap":                  func() Map[string, int] { return NewSyncMap[string, int]() },
+		"SortedSliceMap":           func() Map[string, int] { return NewSortedSliceMap[string, int]() },
+		"ThreadSafeSortedSliceMap": func() Map[string, int] { return NewThreadSafeSortedSliceMap[string, int]() },
+		"LinkedMap":                func() Map[string, int] { return NewLinkedMap[string, int]() },
+		"LRUMap":                   func() Map[string, int] { return NewLRUMap[string, int](10) },
+		"ThreadSafeLRUMap":         func() Map[string, int] { return NewThreadSafeLRUMap[string, int](10) },
+		"MVCCMap":                  func() Map[string, int] { return NewMVCCMap[string, int]() },
+	}
+	for name, factory := range factories {
+		m := factory()
+		m.Store("one", 1)
+		m.Store("two", 2)
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Errorf("%s: Expected no error, but got %v", name, err)
+		}
+
+		decoded := factory()
+		decoded.Store("stale", 0)
+		if err := json.Unmarshal(data, decoded); err != nil {
+			t.Errorf("%s: Expected no error, but got %v", name, err)
+		}
+		if decoded.Contain("stale") || !decoded.Contain("one") || !decoded.Contain("two") {
+			t.Errorf("%s: Expected the decoded map to replace the content, but got %s", name, data)
+		}
+	}
+}
+
+func TestJSON_LinkedMapOrder(t *testing.T) {
+	m := NewLinkedMap[string, int]()
+	for i, key := range []string{"z", "a", "m"} {
+		m.Store(key, i)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeJSON[string, int](&buf, m); err != nil {
+		t.Fatalf("EncodeJSON: Expected no error, but got %v", err)
+	}
+	if buf.String() != `{"z":0,"a":1,"m":2}` {
+		t.Errorf("EncodeJSON: Expected the insertion order, but got %s", buf.String())
+	}
+
+	decoded := NewLinkedMap[string, int]()
+	if err := DecodeJSON[string, int](&buf, decoded); err != nil {
+		t.Fatalf("DecodeJSON: Expected no error, but got %v", err)
+	}
+	if keys := keysOf(decoded); !reflect.DeepEqual(keys, []string{"z", "a", "m"}) {
+		t.Errorf("DecodeJSON: Expected the insertion order, but got %v", keys)
+	}
+}
+
+func TestJSON_Errors(t *testing.T) {
+	if err := EncodeJSON[int, int](&bytes.Buffer{}, NewBoundedMap(NewPureMap[int, int](), 1, NewLRUPolicy[int]())); err != ErrNotIterable {
+		t.Errorf("EncodeJSON: Expected ErrNotIterable, but got %v", err)
+	}
+	for _, data := range []string{`{"1":1}`, `[[1]]`, `[1,2]`, `"map"`, `[[1,1]`} {
+		if err := json.Unmarshal([]byte(data), NewPureMap[int, int]()); err == nil {
+			t.Errorf("Unmarshal: Expected an error for %s, but got none", data)
+		}
+	}
+}
+
+func FuzzJSON_StringKeys(f *testing.F) {
+	f.Add([]byte("a\x00b\x01"))
+	f.Add([]byte("\"\\ \xff"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		m := NewLinkedMap[string, int]()
+		for i := 0; i+1 < len(data); i += 2 {
+			// JSON strings can only hold valid UTF-8
+			m.Store(strings.ToValidUTF8(string(data[i:i+2]), "?"), int(data[i]))
+		}
+		encoded, err := json.Marshal(m)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		decoded := NewLinkedMap[string, int]()
+		if err := json.Unmarshal(encoded, decoded); err != nil {
+			t.Fatalf("Unmarshal %s: %v", encoded, err)
+		}
+		if !reflect.DeepEqual(entriesOf[string, int](m), entriesOf[string, int](decoded)) {
+			t.Errorf("Round trip: Expected %v, but got %v", entriesOf[string, int](m), entriesOf[string, int](decoded))
+		}
+	})
+}
+
+func FuzzJSON_IntKeys(f *testing.F) {
+	f.Add([]byte{1, 2, 3, 255, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		m := NewSortedSliceMap[int64, string]()
+		for i := 0; i+1 < len(data); i += 2 {
+			m.Store(int64(int8(data[i]))<<40|int64(data[i+1]), strings.ToValidUTF8(string(data[i:]), "?"))
+		}
+		encoded, err := json.Marshal(m)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		decoded := NewSortedSliceMap[int64, string]()
+		if err := json.Unmarshal(encoded, decoded); err != nil {
+			t.Fatalf("Unmarshal %s: %v", encoded, err)
+		}
+		if !reflect.DeepEqual(entriesOf[int64, string](m), entriesOf[int64, string](decoded)) {
+			t.Errorf("Round trip: Expected %v, but got %v", entriesOf[int64, string](m), entriesOf[int64, string](decoded))
+		}
+	})
+}
+
+type entry[K comparable, V any] struct {
+	Key K
+	Val V
+}
+
+// entriesOf returns the entries of m in their Range order
+func entriesOf[K comparable, V any](m Map[K, V]) []entry[K, V] {
+	entries := []entry[K, V]{}
+	m.(Iterable[K, V]).Range(func(key K, val V) bool {
+		entries = append(entries, entry[K, V]{key, val})
+		return true
+	})
+	return entries
+}
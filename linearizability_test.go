@@ -0,0 +1,27 @@
+package gomap_test
+
+import (
+	"testing"
+
+	"github.com/lovung/gomap"
+	"github.com/lovung/gomap/gomaptest"
+)
+
+func TestLinearizability(t *testing.T) {
+	backends := map[string]func() gomap.Map[int, string]{
+		"ThreadSafePureMap":           func() gomap.Map[int, string] { return gomap.NewThreadSafePureMap[int, string]() },
+		"ThreadSafeSortedSliceMap":    func() gomap.Map[int, string] { return gomap.NewThreadSafeSortedSliceMap[int, string]() },
+		"ThreadSafeIntSortedSliceMap": func() gomap.Map[int, string] { return gomap.NewThreadSafeIntSortedSliceMap[int, string]() },
+		"SyncMap":                     func() gomap.Map[int, string] { return gomap.NewSyncMap[int, string]() },
+		"ThreadSafeLRUMap":            func() gomap.Map[int, string] { return gomap.NewThreadSafeLRUMap[int, string](1 << 10) },
+		"MVCCMap":                     func() gomap.Map[int, string] { return gomap.NewMVCCMap[int, string]() },
+		"AdaptiveMap": func() gomap.Map[int, string] {
+			return gomap.NewAdaptiveMap[int, string](gomap.WithAdaptiveWindow(8))
+		},
+	}
+	for name, factory := range backends {
+		t.Run(name, func(t *testing.T) {
+			gomaptest.RunLinearizability(t, factory)
+		})
+	}
+}
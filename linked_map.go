@@ -0,0 +1,263 @@
+package gomap
+
+import (
+	"fmt"
+	"io"
+)
+
+// LinkedMap is a Map which remembers the order of its keys
+type LinkedMap[K comparable, V any] interface {
+	Map[K, V]
+	Len() int
+	// Oldest returns the first entry of the order
+	Oldest() (K, V, bool)
+	// Newest returns the last entry of the order
+	Newest() (K, V, bool)
+	// MoveToBack makes key the newest entry, it returns false if key doesn't exist
+	MoveToBack(key K) bool
+	// Range calls f for every entry from the oldest to the newest until f returns false
+	Range(f func(key K, val V) bool)
+}
+
+// WithAccessOrder makes a LinkedMap order its keys by last access
+// instead of insertion, Load and Store moving a key to the back
+func WithAccessOrder() Option {
+	return func(o *option) {
+		o.accessOrder = true
+	}
+}
+
+type linkedEntry[K comparable, V any] struct {
+	key        K
+	val        V
+	prev, next *linkedEntry[K, V]
+}
+
+type linkedMap[K comparable, V any] struct {
+	store       map[K]*linkedEntry[K, V]
+	root        linkedEntry[K, V] // sentinel, root.next is the oldest
+	accessOrder bool
+}
+
+// NewLinkedMap creates a hash map keeping its keys in insertion order,
+// storing an existing key keeps its place.
+// non-thread-safe
+func NewLinkedMap[K comparable, V any](opts ...Option) LinkedMap[K, V] {
+	opt := option{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	m := &linkedMap[K, V]{
+		store:       make(map[K]*linkedEntry[K, V]),
+		accessOrder: opt.accessOrder,
+	}
+	m.root.next = &m.root
+	m.root.prev = &m.root
+	return m
+}
+
+// unlink removes e from the order, marking it with a nil prev.
+// It keeps e.next, which leads a Range holding e to the entries following it.
+func (m *linkedMap[K, V]) unlink(e *linkedEntry[K, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev = nil
+}
+
+func (m *linkedMap[K, V]) pushBack(e *linkedEntry[K, V]) {
+	e.next = &m.root
+	e.prev = m.root.prev
+	m.root.prev.next = e
+	m.root.prev = e
+}
+
+func (m *linkedMap[K, V]) moveToBack(e *linkedEntry[K, V]) {
+	if m.root.prev == e {
+		return
+	}
+	m.unlink(e)
+	m.pushBack(e)
+}
+
+func (m *linkedMap[K, V]) Store(key K, val V) {
+	if e, ok := m.store[key]; ok {
+		e.val = val
+		if m.accessOrder {
+			m.moveToBack(e)
+		}
+		return
+	}
+
+	e := &linkedEntry[K, V]{key: key, val: val}
+	m.store[key] = e
+	m.pushBack(e)
+}
+
+func (m *linkedMap[K, V]) Load(key K) (V, bool) {
+	e, ok := m.store[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if m.accessOrder {
+		m.moveToBack(e)
+	}
+	return e.val, true
+}
+
+func (m *linkedMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	e, ok := m.store[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	m.unlink(e)
+	delete(m.store, key)
+	return e.val, true
+}
+
+func (m *linkedMap[K, V]) Delete(key K) {
+	m.LoadAndDelete(key)
+}
+
+func (m *linkedMap[K, V]) Contain(key K) bool {
+	_, ok := m.store[key]
+	return ok
+}
+
+func (m *linkedMap[K, V]) Clear() {
+	// Unlink the entries, a Range calling Clear stops at the next one.
+	// root.next is nil in a zero map.
+	for e := m.root.next; e != nil && e != &m.root; {
+		next := e.next
+		e.prev, e.next = nil, &m.root
+		e = next
+	}
+	m.store = make(map[K]*linkedEntry[K, V])
+	m.root.next = &m.root
+	m.root.prev = &m.root
+}
+
+func (m *linkedMap[K, V]) Len() int {
+	return len(m.store)
+}
+
+func (m *linkedMap[K, V]) Oldest() (K, V, bool) {
+	return m.entry(m.root.next)
+}
+
+func (m *linkedMap[K, V]) Newest() (K, V, bool) {
+	return m.entry(m.root.prev)
+}
+
+func (m *linkedMap[K, V]) entry(e *linkedEntry[K, V]) (K, V, bool) {
+	if e == &m.root {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+	return e.key, e.val, true
+}
+
+func (m *linkedMap[K, V]) MoveToBack(key K) bool {
+	e, ok := m.store[key]
+	if ok {
+		m.moveToBack(e)
+	}
+	return ok
+}
+
+// Range allows f to delete entries, which are then not visited.
+// An entry moved to the back by f, such as by MoveToBack, may make Range skip the entries it passed.
+func (m *linkedMap[K, V]) Range(f func(key K, val V) bool) {
+	for e := m.root.next; e != &m.root; {
+		// Keep the next entry first, so f can delete the current one
+		next := e.next
+		if !f(e.key, e.val) {
+			return
+		}
+		// The entries deleted by f lead to the ones which followed them
+		for next != &m.root && next.prev == nil {
+			next = next.next
+		}
+		e = next
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *linkedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (m *linkedMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[K, V](data, m)
+}
+
+func (m *linkedMap[K, V]) backendType() BackendType {
+	return BackendLinkedMap
+}
+
+func (m *linkedMap[K, V]) describe() Description {
+	return Description{Backend: "LinkedMap", Complexity: hashComplexity}
+}
+
+// snapshotIndex encodes the order, so a zero map decodes with it
+func (m *linkedMap[K, V]) snapshotIndex() []byte {
+	if m.accessOrder {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// loadSnapshot replaces the content with a snapshot, in the order it was written in.
+// A zero linkedMap, such as the one gob decodes into, takes the order of the snapshot.
+func (m *linkedMap[K, V]) loadSnapshot(r io.Reader, kc Codec[K], vc Codec[V]) (int64, error) {
+	h, index, keys, vals, n, err := readSnapshotEntries(r, kc, vc)
+	if err != nil {
+		return n, err
+	}
+	if m.store == nil {
+		if h.Backend != BackendLinkedMap || len(index) != 1 || index[0] > 1 {
+			return n, fmt.Errorf("%w: no order to decode a zero LinkedMap", ErrBadSnapshot)
+		}
+		m.accessOrder = index[0] == 1
+	}
+
+	m.Clear()
+	for i, key := range keys {
+		m.Store(key, vals[i])
+	}
+	return n, nil
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (m *linkedMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeIndexedSnapshot[K, V](w, BackendLinkedMap, m, DefaultCodec[K](), DefaultCodec[V](), m.snapshotIndex())
+}
+
+// ReadFrom implements the io.ReaderFrom interface, replacing the content with a snapshot
+func (m *linkedMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	return m.loadSnapshot(r, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, see WriteTo
+func (m *linkedMap[K, V]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, see ReadFrom
+func (m *linkedMap[K, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m)
+}
+
+// GobEncode implements the gob.GobEncoder interface
+func (m *linkedMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface
+func (m *linkedMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
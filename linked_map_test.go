@@ -0,0 +1,123 @@
+package gomap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func keysOf[K comparable, V any](m LinkedMap[K, V]) []K {
+	keys := []K{}
+	m.Range(func(key K, val V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+func TestLinkedMap(t *testing.T) {
+	m := NewLinkedMap[string, int]()
+
+	// Test Store and Load methods
+	m.Store("c", 3)
+	m.Store("a", 1)
+	m.Store("b", 2)
+	m.Store("c", 30) // keeps its place
+
+	val, ok := m.Load("c")
+	if !ok || val != 30 {
+		t.Errorf("Load: Expected value 30, but got %d", val)
+	}
+	if keys := keysOf(m); !reflect.DeepEqual(keys, []string{"c", "a", "b"}) {
+		t.Errorf("Range: Expected insertion order [c a b], but got %v", keys)
+	}
+
+	// Test Oldest, Newest and MoveToBack methods
+	if key, val, _ := m.Oldest(); key != "c" || val != 30 {
+		t.Errorf("Oldest: Expected c=30, but got %s=%d", key, val)
+	}
+	if !m.MoveToBack("c") || m.MoveToBack("z") {
+		t.Errorf("MoveToBack: Expected to move only existing keys")
+	}
+	if key, _, _ := m.Newest(); key != "c" {
+		t.Errorf("Newest: Expected c, but got %s", key)
+	}
+
+	// Test LoadAndDelete method
+	val, ok = m.LoadAndDelete("a")
+	if !ok || val != 1 {
+		t.Errorf("LoadAndDelete: Expected value 1, but got %d", val)
+	}
+	if keys := keysOf(m); !reflect.DeepEqual(keys, []string{"b", "c"}) {
+		t.Errorf("Range: Expected [b c], but got %v", keys)
+	}
+
+	// Test Delete method while ranging
+	m.Range(func(key string, val int) bool {
+		m.Delete(key)
+		return true
+	})
+	if m.Len() != 0 {
+		t.Errorf("Delete: Expected map to be empty, but it has %d entries", m.Len())
+	}
+	if _, _, ok := m.Oldest(); ok {
+		t.Errorf("Oldest: Expected no entry in an empty map")
+	}
+
+	// Test Clear method
+	m.Store("a", 1)
+	m.Clear()
+	if m.Contain("a") || len(keysOf(m)) != 0 {
+		t.Errorf("Clear: Expected map to be empty, but it still contains keys")
+	}
+}
+
+func TestLinkedMap_RangeDelete(t *testing.T) {
+	m := NewLinkedMap[int, string]()
+	for i := 0; i < 6; i++ {
+		m.Store(i, "v")
+	}
+
+	// Deleting the current and the next entries skips them
+	var visited []int
+	m.Range(func(key int, val string) bool {
+		visited = append(visited, key)
+		if key == 0 || key == 3 {
+			m.Delete(key)
+			m.Delete(key + 1)
+		}
+		return true
+	})
+	if !reflect.DeepEqual(visited, []int{0, 2, 3, 5}) {
+		t.Errorf("Range: Expected to visit [0 2 3 5], but got %v", visited)
+	}
+	if keys := keysOf(m); !reflect.DeepEqual(keys, []int{2, 5}) {
+		t.Errorf("Range: Expected keys [2 5], but got %v", keys)
+	}
+
+	// Clear stops the iteration
+	visited = nil
+	m.Range(func(key int, val string) bool {
+		visited = append(visited, key)
+		m.Clear()
+		return true
+	})
+	if !reflect.DeepEqual(visited, []int{2}) || m.Len() != 0 {
+		t.Errorf("Range: Expected to visit [2] before Clear, but got %v", visited)
+	}
+}
+
+func TestLinkedMap_AccessOrder(t *testing.T) {
+	m := NewLinkedMap[int, string](WithAccessOrder())
+	m.Store(1, "one")
+	m.Store(2, "two")
+	m.Store(3, "three")
+	m.Load(1)
+	m.Store(2, "deux")
+
+	if keys := keysOf(m); !reflect.DeepEqual(keys, []int{3, 1, 2}) {
+		t.Errorf("Range: Expected access order [3 1 2], but got %v", keys)
+	}
+	if m.Contain(3); !reflect.DeepEqual(keysOf(m), []int{3, 1, 2}) {
+		t.Errorf("Contain: Expected Contain not to count as an access")
+	}
+}
@@ -0,0 +1,317 @@
+package gomap
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Loader loads the value of a key missing in a LoadingMap
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// LoadingMap is a Map which loads the missing keys on demand
+type LoadingMap[K comparable, V any] interface {
+	Map[K, V]
+	// GetOrLoad returns the value of key, calling loader if it is missing.
+	// Concurrent calls for the same key share a single call of loader,
+	// which is cancelled once all of them gave up.
+	GetOrLoad(ctx context.Context, key K, loader Loader[K, V]) (V, error)
+}
+
+// WithRefreshAhead makes a LoadingMap with a default TTL reload an entry in the background
+// once fraction of its TTL has passed, while it keeps returning the current value
+func WithRefreshAhead(fraction float64) Option {
+	return func(o *option) {
+		o.refreshAhead = fraction
+	}
+}
+
+// WithStaleWhileRevalidate makes a LoadingMap with a default TTL keep returning
+// an expired entry for d while it is reloaded in the background
+func WithStaleWhileRevalidate(d time.Duration) Option {
+	return func(o *option) {
+		o.staleWhileRevalidate = d
+	}
+}
+
+// WithRefreshWorkers bounds how many background reloads a LoadingMap runs at once,
+// the reloads over the bound are skipped until a later access
+func WithRefreshWorkers(n int) Option {
+	return func(o *option) {
+		o.refreshWorkers = n
+	}
+}
+
+const defaultRefreshWorkers = 4
+
+// WithNegativeTTL makes a LoadingMap remember the error of a loader for d,
+// so the key is not loaded again until then
+func WithNegativeTTL(d time.Duration) Option {
+	return func(o *option) {
+		o.negativeTTL = d
+	}
+}
+
+type loadCall[V any] struct {
+	done    chan struct{} // closed once val and err are set
+	val     V
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+	stale   bool // the key was written meanwhile, so val must not be stored
+
+	background bool // a refresh, which must not be cancelled by the waiters
+}
+
+type loadFailure struct {
+	err     error
+	expires time.Time
+}
+
+type loadingMap[K comparable, V any] struct {
+	Map[K, V]
+
+	clock                Clock
+	negativeTTL          time.Duration
+	ttl                  time.Duration
+	refreshAhead         float64
+	staleWhileRevalidate time.Duration
+	refreshSlots         chan struct{} // one per running refresh
+
+	mu       sync.Mutex
+	calls    map[K]*loadCall[V]
+	failures map[K]loadFailure
+	expiries map[K]time.Time // only used with a TTL
+}
+
+// entryState is the freshness of an entry of a LoadingMap with a TTL
+type entryState int
+
+const (
+	entryFresh entryState = iota
+	entryRefresh
+	entryExpired
+)
+
+// NewLoadingMap wraps m to load its missing keys on demand.
+// WithDefaultTTL makes the entries expire,
+// WithRefreshAhead and WithStaleWhileRevalidate reload them before they are missed.
+// The writes must go through the returned map, not m.
+// thread-safe if m is thread-safe
+func NewLoadingMap[K comparable, V any](m Map[K, V], opts ...Option) LoadingMap[K, V] {
+	opt := option{clock: systemClock{}, refreshWorkers: defaultRefreshWorkers}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	return &loadingMap[K, V]{
+		Map:                  m,
+		clock:                opt.clock,
+		negativeTTL:          opt.negativeTTL,
+		ttl:                  opt.defaultTTL,
+		refreshAhead:         opt.refreshAhead,
+		staleWhileRevalidate: opt.staleWhileRevalidate,
+		refreshSlots:         make(chan struct{}, max(opt.refreshWorkers, 1)),
+		calls:                make(map[K]*loadCall[V]),
+		failures:             make(map[K]loadFailure),
+		expiries:             make(map[K]time.Time),
+	}
+}
+
+func (m *loadingMap[K, V]) GetOrLoad(ctx context.Context, key K, loader Loader[K, V]) (V, error) {
+	if m.ttl == 0 {
+		if val, ok := m.Map.Load(key); ok {
+			return val, nil
+		}
+	}
+
+	var zero V
+	m.mu.Lock()
+	// The key may have been loaded since
+	if val, ok := m.Map.Load(key); ok {
+		switch m.stateLocked(key) {
+		case entryFresh:
+			m.mu.Unlock()
+			return val, nil
+		case entryRefresh:
+			m.refreshLocked(ctx, key, loader)
+			m.mu.Unlock()
+			return val, nil
+		}
+		// Expired for good, load it like a missing key
+	}
+	if f, ok := m.failures[key]; ok {
+		if m.clock.Now().Before(f.expires) {
+			m.mu.Unlock()
+			return zero, f.err
+		}
+		delete(m.failures, key)
+	}
+	c, ok := m.calls[key]
+	if !ok {
+		// The loader outlives the caller which started it, as long as other callers wait
+		loadCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &loadCall[V]{done: make(chan struct{}), cancel: cancel}
+		m.calls[key] = c
+		go m.load(loadCtx, key, c, loader)
+	}
+	c.waiters++
+	m.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		m.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 && !c.background {
+			c.cancel()
+			c.stale = true
+			if m.calls[key] == c {
+				delete(m.calls, key)
+			}
+		}
+		m.mu.Unlock()
+		return zero, ctx.Err()
+	}
+}
+
+func (m *loadingMap[K, V]) load(ctx context.Context, key K, c *loadCall[V], loader Loader[K, V]) {
+	val, err := loader(ctx, key)
+
+	m.mu.Lock()
+	if m.calls[key] == c {
+		delete(m.calls, key)
+	}
+	if !c.stale {
+		if err == nil {
+			m.storeLocked(key, val)
+		} else if m.negativeTTL > 0 && !c.background {
+			m.failures[key] = loadFailure{err: err, expires: m.clock.Now().Add(m.negativeTTL)}
+		}
+	}
+	c.val, c.err = val, err
+	c.cancel()
+	m.mu.Unlock()
+	close(c.done)
+}
+
+// stateLocked returns the freshness of an existing key, the caller must hold mu
+func (m *loadingMap[K, V]) stateLocked(key K) entryState {
+	expires, ok := m.expiries[key]
+	if !ok {
+		return entryFresh
+	}
+
+	now := m.clock.Now()
+	switch {
+	case !now.Before(expires.Add(m.staleWhileRevalidate)):
+		return entryExpired
+	case !now.Before(expires):
+		return entryRefresh
+	case m.refreshAhead > 0 && !now.Before(expires.Add(-time.Duration((1-m.refreshAhead)*float64(m.ttl)))):
+		return entryRefresh
+	}
+	return entryFresh
+}
+
+// refreshLocked reloads key in the background unless it is already loading
+// or all the refresh workers are busy, the caller must hold mu
+func (m *loadingMap[K, V]) refreshLocked(ctx context.Context, key K, loader Loader[K, V]) {
+	if _, ok := m.calls[key]; ok {
+		return
+	}
+	select {
+	case m.refreshSlots <- struct{}{}:
+	default:
+		return
+	}
+
+	loadCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	c := &loadCall[V]{done: make(chan struct{}), cancel: cancel, background: true}
+	m.calls[key] = c
+	go func() {
+		defer func() { <-m.refreshSlots }()
+		m.load(loadCtx, key, c, loader)
+	}()
+}
+
+// storeLocked stores key with a new expiration, the caller must hold mu
+func (m *loadingMap[K, V]) storeLocked(key K, val V) {
+	m.Map.Store(key, val)
+	if m.ttl > 0 {
+		m.expiries[key] = m.clock.Now().Add(m.ttl)
+	}
+}
+
+// invalidate stops a pending load of key from overwriting a newer write
+func (m *loadingMap[K, V]) invalidate(key K) {
+	if c, ok := m.calls[key]; ok {
+		c.stale = true
+		delete(m.calls, key)
+	}
+	delete(m.failures, key)
+	delete(m.expiries, key)
+}
+
+func (m *loadingMap[K, V]) Load(key K) (V, bool) {
+	if m.ttl == 0 {
+		return m.Map.Load(key)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	val, ok := m.Map.Load(key)
+	if !ok || m.stateLocked(key) == entryExpired {
+		var zero V
+		return zero, false
+	}
+	return val, true
+}
+
+func (m *loadingMap[K, V]) Contain(key K) bool {
+	_, ok := m.Load(key)
+	return ok
+}
+
+func (m *loadingMap[K, V]) Store(key K, val V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.invalidate(key)
+	m.storeLocked(key, val)
+}
+
+func (m *loadingMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.invalidate(key)
+	return m.Map.LoadAndDelete(key)
+}
+
+func (m *loadingMap[K, V]) Delete(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.invalidate(key)
+	m.Map.Delete(key)
+}
+
+func (m *loadingMap[K, V]) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.calls {
+		c.stale = true
+	}
+	m.calls = make(map[K]*loadCall[V])
+	m.failures = make(map[K]loadFailure)
+	m.expiries = make(map[K]time.Time)
+	m.Map.Clear()
+}
+
+func (m *loadingMap[K, V]) describe() Description {
+	return wrapping("LoadingMap", 0, CapThreadSafe|CapFiltered|CapBounded|CapPersistent, Describe(m.Map))
+}
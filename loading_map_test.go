@@ -0,0 +1,251 @@
+package gomap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock which only moves when told to
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestLoadingMap(t *testing.T) {
+	m := NewLoadingMap(NewThreadSafePureMap[int, string]())
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key int) (string, error) {
+		calls.Add(1)
+		<-release
+		return "one", nil
+	}
+
+	// Concurrent misses share one loader call
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := m.GetOrLoad(context.Background(), 1, loader)
+			if err != nil || val != "one" {
+				t.Errorf("GetOrLoad: Expected value 'one', but got '%s' and %v", val, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("GetOrLoad: Expected loader to be called once, but got %d", n)
+	}
+	if val, ok := m.Load(1); !ok || val != "one" {
+		t.Errorf("Load: Expected value 'one', but got '%s'", val)
+	}
+
+	// Hits don't call the loader
+	_, _ = m.GetOrLoad(context.Background(), 1, loader)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("GetOrLoad: Expected loader not to be called again, but got %d calls", n)
+	}
+}
+
+func TestLoadingMap_NegativeTTL(t *testing.T) {
+	clock := newFakeClock()
+	m := NewLoadingMap(NewPureMap[int, string](), WithNegativeTTL(time.Minute), WithClock(clock))
+
+	errNotFound := errors.New("not found")
+	calls := 0
+	loader := func(ctx context.Context, key int) (string, error) {
+		calls++
+		return "", errNotFound
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := m.GetOrLoad(context.Background(), 1, loader); !errors.Is(err, errNotFound) {
+			t.Errorf("GetOrLoad: Expected errNotFound, but got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("NegativeTTL: Expected loader to be called once, but got %d", calls)
+	}
+
+	clock.Advance(time.Minute)
+	_, _ = m.GetOrLoad(context.Background(), 1, loader)
+	if calls != 2 {
+		t.Errorf("NegativeTTL: Expected loader to be called again after the TTL, but got %d calls", calls)
+	}
+
+	// A Store replaces the cached error
+	m.Store(1, "one")
+	if val, err := m.GetOrLoad(context.Background(), 1, loader); err != nil || val != "one" {
+		t.Errorf("GetOrLoad: Expected value 'one', but got '%s' and %v", val, err)
+	}
+}
+
+func TestLoadingMap_Cancel(t *testing.T) {
+	m := NewLoadingMap(NewThreadSafePureMap[int, string]())
+
+	cancelled := make(chan struct{})
+	loader := func(ctx context.Context, key int) (string, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return "", ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.GetOrLoad(ctx, 1, loader); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetOrLoad: Expected context.DeadlineExceeded, but got %v", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Errorf("GetOrLoad: Expected the loader to be cancelled once no caller waits")
+	}
+	if m.Contain(1) {
+		t.Errorf("GetOrLoad: Expected key 1 not to exist, but it does")
+	}
+}
+
+// waitFor polls cond until it is true or a second passed
+func waitFor(cond func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+func TestLoadingMap_RefreshAhead(t *testing.T) {
+	clock := newFakeClock()
+	m := NewLoadingMap(NewThreadSafePureMap[int, int](),
+		WithDefaultTTL(time.Minute), WithRefreshAhead(0.5), WithClock(clock))
+
+	var version atomic.Int32
+	loader := func(ctx context.Context, key int) (int, error) {
+		return int(version.Add(1)), nil
+	}
+	ctx := context.Background()
+
+	if val, _ := m.GetOrLoad(ctx, 1, loader); val != 1 {
+		t.Errorf("GetOrLoad: Expected version 1, but got %d", val)
+	}
+
+	// Before the refresh point the value is served from the map
+	clock.Advance(20 * time.Second)
+	if val, _ := m.GetOrLoad(ctx, 1, loader); val != 1 || version.Load() != 1 {
+		t.Errorf("GetOrLoad: Expected version 1 without reload, but got %d", val)
+	}
+
+	// After it the old value is returned while it is reloaded
+	clock.Advance(20 * time.Second)
+	if val, _ := m.GetOrLoad(ctx, 1, loader); val != 1 {
+		t.Errorf("RefreshAhead: Expected the old version 1 during the refresh, but got %d", val)
+	}
+	if !waitFor(func() bool { val, _ := m.Load(1); return val == 2 }) {
+		t.Errorf("RefreshAhead: Expected the value to be refreshed in the background")
+	}
+
+	// The refresh restarted the TTL
+	clock.Advance(50 * time.Second)
+	if val, ok := m.Load(1); !ok || val != 2 {
+		t.Errorf("Load: Expected version 2 to still be valid, but got %d", val)
+	}
+}
+
+func TestLoadingMap_StaleWhileRevalidate(t *testing.T) {
+	clock := newFakeClock()
+	m := NewLoadingMap(NewThreadSafePureMap[int, int](),
+		WithDefaultTTL(time.Minute), WithStaleWhileRevalidate(time.Minute), WithClock(clock))
+
+	var version atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key int) (int, error) {
+		if version.Load() > 0 {
+			<-release
+		}
+		return int(version.Add(1)), nil
+	}
+	ctx := context.Background()
+	_, _ = m.GetOrLoad(ctx, 1, loader)
+
+	// Within the stale window the stale value is returned at once
+	clock.Advance(90 * time.Second)
+	if val, _ := m.GetOrLoad(ctx, 1, loader); val != 1 {
+		t.Errorf("StaleWhileRevalidate: Expected the stale version 1, but got %d", val)
+	}
+	if val, ok := m.Load(1); !ok || val != 1 {
+		t.Errorf("Load: Expected the stale version 1, but got %d", val)
+	}
+	close(release)
+	if !waitFor(func() bool { val, _ := m.Load(1); return val == 2 }) {
+		t.Errorf("StaleWhileRevalidate: Expected the value to be revalidated in the background")
+	}
+
+	// Past the stale window the entry is a miss
+	clock.Advance(2 * time.Minute)
+	if _, ok := m.Load(1); ok {
+		t.Errorf("Load: Expected key 1 to be expired, but it isn't")
+	}
+	if val, _ := m.GetOrLoad(ctx, 1, loader); val != 3 {
+		t.Errorf("GetOrLoad: Expected a synchronous reload to version 3, but got %d", val)
+	}
+}
+
+func TestLoadingMap_RefreshWorkers(t *testing.T) {
+	clock := newFakeClock()
+	m := NewLoadingMap(NewThreadSafePureMap[int, int](),
+		WithDefaultTTL(time.Minute), WithRefreshAhead(0.5), WithRefreshWorkers(1), WithClock(clock))
+
+	release := make(chan struct{})
+	var refreshes atomic.Int32
+	loader := func(ctx context.Context, key int) (int, error) {
+		if clock.Now().After(time.Unix(0, 0)) {
+			refreshes.Add(1)
+			<-release
+		}
+		return key, nil
+	}
+	ctx := context.Background()
+	for key := 0; key < 3; key++ {
+		_, _ = m.GetOrLoad(ctx, key, loader)
+	}
+
+	clock.Advance(40 * time.Second)
+	for key := 0; key < 3; key++ {
+		_, _ = m.GetOrLoad(ctx, key, loader)
+	}
+	waitFor(func() bool { return refreshes.Load() == 1 })
+	time.Sleep(10 * time.Millisecond)
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("RefreshWorkers: Expected 1 refresh at once, but got %d", n)
+	}
+	close(release)
+}
@@ -0,0 +1,656 @@
+package gomap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// EvictReason tells why an entry left a BoundedMap
+type EvictReason int
+
+const (
+	// EvictCapacity means the entry was evicted to make room for another one
+	EvictCapacity EvictReason = iota
+	// EvictDelete means the entry was deleted by Delete or LoadAndDelete
+	EvictDelete
+	// EvictClear means the entry was removed by Clear
+	EvictClear
+)
+
+// BoundedMap is a Map which holds at most Cap entries,
+// and at most MaxCost of total cost if it was created WithMaxCost.
+// Store silently drops an entry costing more than MaxCost, TryStore returns ErrCostExceeded,
+// and both evict the entry the key had, so a Load never returns the value it replaced.
+type BoundedMap[K comparable, V any] interface {
+	Map[K, V]
+	TryStore(key K, val V) error
+	Len() int
+	Cap() int
+	Cost() int64
+	MaxCost() int64
+	// Headroom returns how much cost the map can take before evicting
+	Headroom() int64
+	// OnEvict sets the function called for every entry leaving the map.
+	// It is called with the lock of the map held, so it must not use the map.
+	OnEvict(fn func(key K, val V, reason EvictReason))
+	Stats() Stats
+}
+
+// lruEntry is an element of the intrusive recency list
+type lruEntry[K comparable, V any] struct {
+	key        K
+	val        V
+	cost       int64
+	used       uint64 // tick of the last use, in the shards of a threadSafeLRUMap
+	prev, next *lruEntry[K, V]
+}
+
+type lruMap[K comparable, V any] struct {
+	store    map[K]*lruEntry[K, V]
+	root     lruEntry[K, V] // sentinel, root.next is the most recently used
+	capacity int
+	onEvict  func(key K, val V, reason EvictReason)
+	stats    Stats
+
+	cost     int64
+	maxCost  int64 // budget of the entries, an entry costing more is rejected
+	costFunc func(key K, val V) int64
+
+	// The shards of a threadSafeLRUMap share their total cost and the clock of the uses
+	total *atomic.Int64
+	ticks *atomic.Uint64
+}
+
+// NewLRUMap creates a map holding at most capacity entries,
+// which evicts the least recently used entry when it is full.
+// Both Load and Store count as a use.
+// capacity can be 0 if the map is bounded WithMaxCost only.
+// non-thread-safe
+func NewLRUMap[K comparable, V any](capacity int, opts ...Option) BoundedMap[K, V] {
+	opt := option{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	return newLRUMap[K, V](capacityOf(capacity, opt), opt.maxCost, costFuncOf[K, V](opt))
+}
+
+func newLRUMap[K comparable, V any](capacity int, maxCost int64, costFunc func(K, V) int64) *lruMap[K, V] {
+	m := &lruMap[K, V]{}
+	m.init(capacity, maxCost, costFunc)
+	return m
+}
+
+func (m *lruMap[K, V]) init(capacity int, maxCost int64, costFunc func(K, V) int64) {
+	*m = lruMap[K, V]{
+		store:    make(map[K]*lruEntry[K, V]),
+		capacity: capacity,
+		maxCost:  maxCost,
+		costFunc: costFunc,
+	}
+	m.root.next = &m.root
+	m.root.prev = &m.root
+}
+
+func (m *lruMap[K, V]) unlink(e *lruEntry[K, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev = nil
+	e.next = nil
+}
+
+func (m *lruMap[K, V]) pushFront(e *lruEntry[K, V]) {
+	if m.ticks != nil {
+		e.used = m.ticks.Add(1)
+	}
+	e.prev = &m.root
+	e.next = m.root.next
+	m.root.next.prev = e
+	m.root.next = e
+}
+
+func (m *lruMap[K, V]) moveToFront(e *lruEntry[K, V]) {
+	if m.root.next == e {
+		if m.ticks != nil {
+			e.used = m.ticks.Add(1)
+		}
+		return
+	}
+	m.unlink(e)
+	m.pushFront(e)
+}
+
+func (m *lruMap[K, V]) evict(e *lruEntry[K, V], reason EvictReason) {
+	m.unlink(e)
+	delete(m.store, e.key)
+	m.addCost(-e.cost)
+	if reason == EvictCapacity {
+		m.stats.Evictions++
+	}
+	if m.onEvict != nil {
+		m.onEvict(e.key, e.val, reason)
+	}
+}
+
+func (m *lruMap[K, V]) Store(key K, val V) {
+	_ = m.TryStore(key, val)
+}
+
+func (m *lruMap[K, V]) TryStore(key K, val V) error {
+	cost := m.costFunc(key, val)
+	e, ok := m.store[key]
+	if m.maxCost > 0 && cost > m.maxCost {
+		// The old value must not outlive the write which replaced it
+		if ok {
+			m.evict(e, EvictCapacity)
+		}
+		return ErrCostExceeded
+	}
+
+	if ok {
+		m.addCost(cost - e.cost)
+		e.val = val
+		e.cost = cost
+		m.moveToFront(e)
+	} else {
+		e = &lruEntry[K, V]{key: key, val: val, cost: cost}
+		m.store[key] = e
+		m.addCost(cost)
+		m.pushFront(e)
+	}
+
+	// The entry just stored fits in the budget alone, so the older ones are evicted first
+	for m.root.prev != e && (len(m.store) > m.capacity || (m.maxCost > 0 && m.cost > m.maxCost)) {
+		m.evict(m.root.prev, EvictCapacity)
+	}
+	return nil
+}
+
+func (m *lruMap[K, V]) addCost(d int64) {
+	m.cost += d
+	if m.total != nil {
+		m.total.Add(d)
+	}
+}
+
+func (m *lruMap[K, V]) Load(key K) (V, bool) {
+	e, ok := m.store[key]
+	if !ok {
+		m.stats.Misses++
+		var zero V
+		return zero, false
+	}
+	m.stats.Hits++
+	m.moveToFront(e)
+	return e.val, true
+}
+
+func (m *lruMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	e, ok := m.store[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	m.evict(e, EvictDelete)
+	return e.val, true
+}
+
+func (m *lruMap[K, V]) Delete(key K) {
+	if e, ok := m.store[key]; ok {
+		m.evict(e, EvictDelete)
+	}
+}
+
+// Contain doesn't count as a use
+func (m *lruMap[K, V]) Contain(key K) bool {
+	_, ok := m.store[key]
+	return ok
+}
+
+func (m *lruMap[K, V]) Clear() {
+	for m.root.next != &m.root {
+		m.evict(m.root.next, EvictClear)
+	}
+}
+
+func (m *lruMap[K, V]) Len() int {
+	return len(m.store)
+}
+
+// Range goes from the least to the most recently used entry,
+// it doesn't count as a use
+func (m *lruMap[K, V]) Range(f func(key K, val V) bool) {
+	for e := m.root.prev; e != &m.root; e = e.prev {
+		if !f(e.key, e.val) {
+			return
+		}
+	}
+}
+
+func (m *lruMap[K, V]) Cap() int {
+	return m.capacity
+}
+
+func (m *lruMap[K, V]) Cost() int64 {
+	return m.cost
+}
+
+func (m *lruMap[K, V]) MaxCost() int64 {
+	return m.maxCost
+}
+
+func (m *lruMap[K, V]) Headroom() int64 {
+	return headroom(m.maxCost, m.cost)
+}
+
+func (m *lruMap[K, V]) OnEvict(fn func(key K, val V, reason EvictReason)) {
+	m.onEvict = fn
+}
+
+func (m *lruMap[K, V]) Stats() Stats {
+	return m.stats
+}
+
+const (
+	defaultLRUShards = 16
+	// maxLRUShards bounds the shards a snapshot can ask for
+	maxLRUShards = 1 << 16
+)
+
+// WithShards sets the number of independently locked shards of a thread-safe map
+func WithShards(n int) Option {
+	return func(o *option) {
+		o.shards = n
+	}
+}
+
+type lruShard[K comparable, V any] struct {
+	mu sync.Mutex
+	*lruMap[K, V]
+}
+
+type threadSafeLRUMap[K comparable, V any] struct {
+	shards   []*lruShard[K, V]
+	capacity int
+	maxCost  int64
+
+	cost  atomic.Int64  // total cost of the shards
+	ticks atomic.Uint64 // clock of the uses of the entries
+}
+
+// NewThreadSafeLRUMap creates a thread-safe NewLRUMap.
+// The keys are spread over shards with their own lock and recency list.
+// The capacity is split between the shards, so the entry evicted to make room
+// is the least recently used of its shard.
+// The max cost is shared by the shards: a Store going over it evicts
+// the least recently used entries of all the shards.
+func NewThreadSafeLRUMap[K comparable, V any](capacity int, opts ...Option) BoundedMap[K, V] {
+	opt := option{shards: defaultLRUShards}
+	for _, o := range opts {
+		o(&opt)
+	}
+	m := &threadSafeLRUMap[K, V]{}
+	m.init(capacityOf(capacity, opt), opt.maxCost, opt.shards, costFuncOf[K, V](opt))
+	return m
+}
+
+func (m *threadSafeLRUMap[K, V]) init(capacity int, maxCost int64, n int, costFunc func(K, V) int64) {
+	if n > capacity {
+		n = capacity
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	m.shards = make([]*lruShard[K, V], n)
+	m.capacity = capacity
+	m.maxCost = maxCost
+	m.cost.Store(0)
+	for i := range m.shards {
+		// Spread the capacity so the shards add up to it
+		shardCap := capacity / n
+		if i < capacity%n {
+			shardCap++
+		}
+		// Every shard rejects the entries over the whole budget, and evicts its own entries
+		// when it holds more than the budget by itself
+		shard := newLRUMap(shardCap, maxCost, costFunc)
+		shard.total = &m.cost
+		shard.ticks = &m.ticks
+		m.shards[i] = &lruShard[K, V]{lruMap: shard}
+	}
+}
+
+func (m *threadSafeLRUMap[K, V]) shard(key K) *lruShard[K, V] {
+	return m.shards[hashKey(key, 0)%uint64(len(m.shards))]
+}
+
+func (m *threadSafeLRUMap[K, V]) Store(key K, val V) {
+	_ = m.TryStore(key, val)
+}
+
+func (m *threadSafeLRUMap[K, V]) TryStore(key K, val V) error {
+	s := m.shard(key)
+	s.mu.Lock()
+	err := s.TryStore(key, val)
+	s.mu.Unlock()
+	if err == nil {
+		m.evictOverCost(key)
+	}
+	return err
+}
+
+// evictOverCost evicts the least recently used entries of all the shards
+// until the total cost is within the budget, keeping the entry of key just stored.
+// It locks a single shard at a time.
+func (m *threadSafeLRUMap[K, V]) evictOverCost(key K) {
+	for m.maxCost > 0 && m.cost.Load() > m.maxCost {
+		var (
+			victim *lruShard[K, V]
+			oldest uint64 = math.MaxUint64
+		)
+		for _, s := range m.shards {
+			s.mu.Lock()
+			if e := s.root.prev; e != &s.root && e.key != key && e.used < oldest {
+				victim, oldest = s, e.used
+			}
+			s.mu.Unlock()
+		}
+		if victim == nil {
+			return
+		}
+
+		// The shard may have changed since, its least recently used entry is evicted anyway
+		victim.mu.Lock()
+		if e := victim.root.prev; e != &victim.root && e.key != key && m.cost.Load() > m.maxCost {
+			victim.evict(e, EvictCapacity)
+		}
+		victim.mu.Unlock()
+	}
+}
+
+func (m *threadSafeLRUMap[K, V]) Load(key K) (V, bool) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Load(key)
+}
+
+func (m *threadSafeLRUMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.LoadAndDelete(key)
+}
+
+func (m *threadSafeLRUMap[K, V]) Delete(key K) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Delete(key)
+}
+
+func (m *threadSafeLRUMap[K, V]) Contain(key K) bool {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Contain(key)
+}
+
+func (m *threadSafeLRUMap[K, V]) Clear() {
+	for _, s := range m.shards {
+		s.mu.Lock()
+		s.Clear()
+		s.mu.Unlock()
+	}
+}
+
+func (m *threadSafeLRUMap[K, V]) Len() int {
+	n := 0
+	for _, s := range m.shards {
+		s.mu.Lock()
+		n += s.Len()
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// Range holds the lock of every shard, so it sees a consistent state of the map.
+// It goes shard by shard and doesn't count as a use.
+func (m *threadSafeLRUMap[K, V]) Range(f func(key K, val V) bool) {
+	for _, s := range m.shards {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	for _, s := range m.shards {
+		more := true
+		s.Range(func(key K, val V) bool {
+			more = f(key, val)
+			return more
+		})
+		if !more {
+			return
+		}
+	}
+}
+
+func (m *threadSafeLRUMap[K, V]) Cap() int {
+	return m.capacity
+}
+
+func (m *threadSafeLRUMap[K, V]) Cost() int64 {
+	return m.cost.Load()
+}
+
+func (m *threadSafeLRUMap[K, V]) MaxCost() int64 {
+	return m.maxCost
+}
+
+func (m *threadSafeLRUMap[K, V]) Headroom() int64 {
+	return headroom(m.maxCost, m.Cost())
+}
+
+func (m *threadSafeLRUMap[K, V]) OnEvict(fn func(key K, val V, reason EvictReason)) {
+	for _, s := range m.shards {
+		s.mu.Lock()
+		s.OnEvict(fn)
+		s.mu.Unlock()
+	}
+}
+
+func (m *threadSafeLRUMap[K, V]) Stats() Stats {
+	var stats Stats
+	for _, s := range m.shards {
+		s.mu.Lock()
+		stats = stats.add(s.Stats())
+		s.mu.Unlock()
+	}
+	return stats
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *lruMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (m *lruMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[K, V](data, m)
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *threadSafeLRUMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (m *threadSafeLRUMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[K, V](data, m)
+}
+
+func (m *lruMap[K, V]) backendType() BackendType {
+	return BackendLRUMap
+}
+
+func (m *lruMap[K, V]) describe() Description {
+	return Description{Backend: "LRUMap", Capabilities: CapBounded, Complexity: hashComplexity}
+}
+
+// snapshotIndex encodes the bounds, so a zero map decodes with them
+func (m *lruMap[K, V]) snapshotIndex() []byte {
+	return appendLRUBounds(nil, m.capacity, m.maxCost, 1)
+}
+
+// loadSnapshot replaces the content with a snapshot, in the recency order it was written in.
+// A zero lruMap, such as the one gob decodes into, takes the bounds of the snapshot,
+// and its entries cost 1 as the cost function is not encoded.
+func (m *lruMap[K, V]) loadSnapshot(r io.Reader, kc Codec[K], vc Codec[V]) (int64, error) {
+	h, index, keys, vals, n, err := readSnapshotEntries(r, kc, vc)
+	if err != nil {
+		return n, err
+	}
+	if m.store == nil {
+		capacity, maxCost, _, ok := decodeLRUBounds(h, index)
+		if !ok {
+			return n, fmt.Errorf("%w: no bounds to decode a zero LRUMap", ErrBadSnapshot)
+		}
+		m.init(capacity, maxCost, costFuncOf[K, V](option{}))
+	}
+
+	m.Clear()
+	for i, key := range keys {
+		m.Store(key, vals[i])
+	}
+	return n, nil
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (m *lruMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeIndexedSnapshot[K, V](w, BackendLRUMap, m, DefaultCodec[K](), DefaultCodec[V](), m.snapshotIndex())
+}
+
+// ReadFrom implements the io.ReaderFrom interface, replacing the content with a snapshot
+func (m *lruMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	return m.loadSnapshot(r, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+func (m *threadSafeLRUMap[K, V]) backendType() BackendType {
+	return BackendThreadSafeLRUMap
+}
+
+func (m *threadSafeLRUMap[K, V]) describe() Description {
+	return Description{Backend: "ThreadSafeLRUMap", Capabilities: CapThreadSafe | CapBounded, Complexity: hashComplexity}
+}
+
+// snapshotIndex encodes the bounds and the number of shards, so a zero map decodes with them
+func (m *threadSafeLRUMap[K, V]) snapshotIndex() []byte {
+	return appendLRUBounds(nil, m.capacity, m.maxCost, len(m.shards))
+}
+
+// loadSnapshot replaces the content with a snapshot.
+// A zero threadSafeLRUMap, such as the one gob decodes into, takes the bounds and the shards of the snapshot,
+// and its entries cost 1 as the cost function is not encoded.
+// It initializes a zero map, so it must not run concurrently with the other methods then.
+func (m *threadSafeLRUMap[K, V]) loadSnapshot(r io.Reader, kc Codec[K], vc Codec[V]) (int64, error) {
+	h, index, keys, vals, n, err := readSnapshotEntries(r, kc, vc)
+	if err != nil {
+		return n, err
+	}
+	if m.shards == nil {
+		capacity, maxCost, shards, ok := decodeLRUBounds(h, index)
+		if !ok {
+			return n, fmt.Errorf("%w: no bounds to decode a zero ThreadSafeLRUMap", ErrBadSnapshot)
+		}
+		m.init(capacity, maxCost, shards, costFuncOf[K, V](option{}))
+	}
+
+	m.Clear()
+	for i, key := range keys {
+		m.Store(key, vals[i])
+	}
+	return n, nil
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (m *threadSafeLRUMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeIndexedSnapshot[K, V](w, BackendThreadSafeLRUMap, m, DefaultCodec[K](), DefaultCodec[V](), m.snapshotIndex())
+}
+
+// ReadFrom implements the io.ReaderFrom interface, replacing the content with a snapshot
+func (m *threadSafeLRUMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	return m.loadSnapshot(r, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// appendLRUBounds encodes the bounds of an LRU map, written as the index of its snapshots
+func appendLRUBounds(index []byte, capacity int, maxCost int64, shards int) []byte {
+	index = binary.AppendUvarint(index, uint64(capacity))
+	index = binary.AppendVarint(index, maxCost)
+	return binary.AppendUvarint(index, uint64(shards))
+}
+
+// decodeLRUBounds decodes the bounds of the snapshot of an LRU map
+func decodeLRUBounds(h SnapshotHeader, index []byte) (capacity int, maxCost int64, shards int, ok bool) {
+	if h.Backend != BackendLRUMap && h.Backend != BackendThreadSafeLRUMap {
+		return 0, 0, 0, false
+	}
+	c, n := binary.Uvarint(index)
+	if n <= 0 || c < 1 || c > math.MaxInt {
+		return 0, 0, 0, false
+	}
+	index = index[n:]
+	maxCost, n = binary.Varint(index)
+	if n <= 0 || maxCost < 0 {
+		return 0, 0, 0, false
+	}
+	index = index[n:]
+	// A corrupted count of shards must not allocate them
+	s, n := binary.Uvarint(index)
+	if n <= 0 || n != len(index) || s < 1 || s > maxLRUShards {
+		return 0, 0, 0, false
+	}
+	return int(c), maxCost, int(s), true
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, see WriteTo
+func (m *lruMap[K, V]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, see ReadFrom
+func (m *lruMap[K, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m)
+}
+
+// GobEncode implements the gob.GobEncoder interface
+func (m *lruMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface
+func (m *lruMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, see WriteTo
+func (m *threadSafeLRUMap[K, V]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, see ReadFrom
+func (m *threadSafeLRUMap[K, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m)
+}
+
+// GobEncode implements the gob.GobEncoder interface
+func (m *threadSafeLRUMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface
+func (m *threadSafeLRUMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
@@ -0,0 +1,96 @@
+package gomap
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestLRUMap(t *testing.T) {
+	m := NewLRUMap[int, string](2)
+
+	type eviction struct {
+		key    int
+		reason EvictReason
+	}
+	var evicted []eviction
+	m.OnEvict(func(key int, val string, reason EvictReason) {
+		evicted = append(evicted, eviction{key, reason})
+	})
+
+	m.Store(1, "one")
+	m.Store(2, "two")
+	m.Load(1) // 2 is now the least recently used
+	m.Store(3, "three")
+
+	if m.Contain(2) {
+		t.Errorf("Store: Expected key 2 to be evicted, but it still exists")
+	}
+	if !m.Contain(1) || !m.Contain(3) {
+		t.Errorf("Store: Expected keys 1 and 3 to exist, but they don't")
+	}
+	if m.Len() != 2 || m.Cap() != 2 {
+		t.Errorf("Len: Expected 2 entries out of 2, but got %d out of %d", m.Len(), m.Cap())
+	}
+
+	// Updating an entry is a use too
+	m.Store(1, "uno")
+	m.Store(4, "four")
+	if m.Contain(3) {
+		t.Errorf("Store: Expected key 3 to be evicted, but it still exists")
+	}
+	if val, _ := m.Load(1); val != "uno" {
+		t.Errorf("Load: Expected value 'uno', but got '%s'", val)
+	}
+
+	m.Delete(1)
+	m.Clear()
+
+	expected := []eviction{{2, EvictCapacity}, {3, EvictCapacity}, {1, EvictDelete}, {4, EvictClear}}
+	if len(evicted) != len(expected) {
+		t.Fatalf("OnEvict: Expected %v, but got %v", expected, evicted)
+	}
+	for i := range expected {
+		if evicted[i] != expected[i] {
+			t.Errorf("OnEvict: Expected %v, but got %v", expected[i], evicted[i])
+		}
+	}
+	if m.Len() != 0 {
+		t.Errorf("Clear: Expected map to be empty, but it has %d entries", m.Len())
+	}
+}
+
+func TestThreadSafeLRUMap(t *testing.T) {
+	m := NewThreadSafeLRUMap[int, int](100, WithShards(4))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				m.Store(g*1000+i, i)
+				m.Load(i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if m.Len() != 100 {
+		t.Errorf("Len: Expected the map to be full with 100 entries, but got %d", m.Len())
+	}
+	m.Store(-1, 1)
+	if _, ok := m.Load(-1); !ok {
+		t.Errorf("Load: Expected the last stored key to exist, but it doesn't")
+	}
+}
+
+func TestThreadSafeLRUMap_NegativeZero(t *testing.T) {
+	// -0 equals 0, so both are routed to the same shard
+	m := NewThreadSafeLRUMap[float64, int](100, WithShards(64))
+	m.Store(math.Copysign(0, -1), 1)
+	m.Store(0, 2)
+	if val, _ := m.Load(math.Copysign(0, -1)); m.Len() != 1 || val != 2 {
+		t.Errorf("Store: Expected a single entry of 2, but got %d entries and %d", m.Len(), val)
+	}
+}
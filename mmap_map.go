@@ -0,0 +1,345 @@
+package gomap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Memory-mapped file format, all the integers are little-endian:
+//
+//	magic   "GMMF"
+//	version 1 byte
+//	key     1 byte, how the keys are encoded
+//	        2 reserved bytes
+//	count   8 bytes, the number of entries
+//	slots   count slots of 16 bytes, sorted by key:
+//	    keyEnd 8 bytes, offset in data of the end of the key
+//	    valEnd 8 bytes, offset in data of the end of the value
+//	data    the key and the value of every entry, an entry starting at the valEnd of the previous one
+//
+// The keys are encoded so their byte order is the order of the keys:
+// the integers are big-endian, with the sign bit flipped for the signed ones.
+
+const (
+	mmapMagic      = "GMMF"
+	mmapVersion    = 1
+	mmapHeaderSize = 16
+	mmapSlotSize   = 16
+
+	mmapSignBit uint64 = 1 << 63
+)
+
+const (
+	mmapSignedKey byte = iota + 1
+	mmapUnsignedKey
+	mmapStringKey
+)
+
+// ErrBadMmapFile is returned when a file is not a memory-mapped map of the expected key type
+var ErrBadMmapFile = errors.New("gomap: not a map file")
+
+// MmapKey is the type of the keys of a memory-mapped map
+type MmapKey interface {
+	constraints.Integer | ~string
+}
+
+// MmapMap is a read-only Map served from a memory-mapped file.
+// Its write methods panic with ErrReadOnly.
+type MmapMap[K MmapKey, V any] interface {
+	Map[K, V]
+	Iterable[K, V]
+	// Close unmaps the file, the map must not be used afterwards
+	Close() error
+}
+
+// WithValueCodec sets how the values of a map file are encoded, DefaultCodec by default.
+// V must be the value type of the map.
+func WithValueCodec[V any](c Codec[V]) Option {
+	return func(o *option) {
+		o.valueCodec = c
+	}
+}
+
+// valueCodecOf returns the value codec of opt for a map of V
+func valueCodecOf[V any](opt option) Codec[V] {
+	if opt.valueCodec == nil {
+		return DefaultCodec[V]()
+	}
+	c, ok := opt.valueCodec.(Codec[V])
+	if !ok {
+		panic(fmt.Sprintf("gomap: WithValueCodec got %T, expected Codec[%T]", opt.valueCodec, *new(V)))
+	}
+	return c
+}
+
+func mmapKeyKind[K MmapKey]() byte {
+	switch reflect.TypeOf((*K)(nil)).Elem().Kind() {
+	case reflect.String:
+		return mmapStringKey
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return mmapSignedKey
+	}
+	return mmapUnsignedKey
+}
+
+// appendMmapKey appends the ordered encoding of key
+func appendMmapKey[K MmapKey](buf []byte, kind byte, key K) []byte {
+	rv := reflect.ValueOf(key)
+	switch kind {
+	case mmapStringKey:
+		return append(buf, rv.String()...)
+	case mmapSignedKey:
+		return binary.BigEndian.AppendUint64(buf, uint64(rv.Int())^mmapSignBit)
+	}
+	return binary.BigEndian.AppendUint64(buf, rv.Uint())
+}
+
+func decodeMmapKey[K MmapKey](kind byte, b []byte) K {
+	var key K
+	rv := reflect.ValueOf(&key).Elem()
+	switch kind {
+	case mmapStringKey:
+		rv.SetString(string(b))
+	case mmapSignedKey:
+		rv.SetInt(int64(binary.BigEndian.Uint64(b) ^ mmapSignBit))
+	default:
+		rv.SetUint(binary.BigEndian.Uint64(b))
+	}
+	return key
+}
+
+// WriteMmapFile writes the entries of m to path, sorted by key, in a layout OpenMmapMap can map.
+// The file is written aside and renamed, so it can replace a file in use.
+// m must be Iterable, WithValueCodec sets how the values are encoded.
+func WriteMmapFile[K MmapKey, V any](path string, m Map[K, V], opts ...Option) error {
+	opt := option{}
+	for _, o := range opts {
+		o(&opt)
+	}
+	vc := valueCodecOf[V](opt)
+
+	it, ok := m.(Iterable[K, V])
+	if !ok {
+		return ErrNotIterable
+	}
+
+	// Encode in a single Range, an entry being the key then the value
+	kind := mmapKeyKind[K]()
+	var (
+		data  []byte
+		spans [][3]int // start, key end and value end of every entry in data
+		err   error
+	)
+	it.Range(func(key K, val V) bool {
+		start := len(data)
+		data = appendMmapKey(data, kind, key)
+		keyEnd := len(data)
+		if data, err = vc.Append(data, val); err != nil {
+			return false
+		}
+		spans = append(spans, [3]int{start, keyEnd, len(data)})
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(spans, func(a, b [3]int) int {
+		return bytes.Compare(data[a[0]:a[1]], data[b[0]:b[1]])
+	})
+
+	f, err := os.Create(path + tempSuffix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path + tempSuffix)
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	header := append([]byte(mmapMagic), mmapVersion, kind, 0, 0)
+	header = binary.LittleEndian.AppendUint64(header, uint64(len(spans)))
+	w.Write(header)
+	var end uint64
+	slot := make([]byte, 0, mmapSlotSize)
+	for _, s := range spans {
+		slot = binary.LittleEndian.AppendUint64(slot[:0], end+uint64(s[1]-s[0]))
+		end += uint64(s[2] - s[0])
+		slot = binary.LittleEndian.AppendUint64(slot, end)
+		w.Write(slot)
+	}
+	for _, s := range spans {
+		w.Write(data[s[0]:s[2]])
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(path+tempSuffix, path)
+}
+
+type mmapMap[K MmapKey, V any] struct {
+	file  []byte // the whole mapped file
+	slots []byte
+	data  []byte
+	count int
+	kind  byte
+	vc    Codec[V]
+}
+
+// OpenMmapMap maps a file written by WriteMmapFile.
+// The file is not read at open, Load and Contain binary search the mapped pages,
+// which the operating system loads on demand.
+// WithValueCodec must match the codec the file was written with.
+// thread-safe
+func OpenMmapMap[K MmapKey, V any](path string, opts ...Option) (MmapMap[K, V], error) {
+	opt := option{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	file, err := mmapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMmapMap[K](file, valueCodecOf[V](opt))
+	if err != nil {
+		munmapFile(file)
+		return nil, err
+	}
+	return m, nil
+}
+
+func newMmapMap[K MmapKey, V any](file []byte, vc Codec[V]) (*mmapMap[K, V], error) {
+	kind := mmapKeyKind[K]()
+	if len(file) < mmapHeaderSize || string(file[:len(mmapMagic)]) != mmapMagic ||
+		file[len(mmapMagic)] != mmapVersion || file[len(mmapMagic)+1] != kind {
+		return nil, ErrBadMmapFile
+	}
+	count := binary.LittleEndian.Uint64(file[8:])
+	if count > uint64(len(file)-mmapHeaderSize)/mmapSlotSize {
+		return nil, ErrBadMmapFile
+	}
+	dataStart := mmapHeaderSize + int(count)*mmapSlotSize
+	m := &mmapMap[K, V]{
+		file:  file,
+		slots: file[mmapHeaderSize:dataStart],
+		data:  file[dataStart:],
+		count: int(count),
+		kind:  kind,
+		vc:    vc,
+	}
+	if count > 0 && binary.LittleEndian.Uint64(m.slots[len(m.slots)-8:]) != uint64(len(m.data)) {
+		return nil, ErrBadMmapFile
+	}
+	return m, nil
+}
+
+// entry returns the encoded key and value of the i-th entry
+func (m *mmapMap[K, V]) entry(i int) (key, val []byte) {
+	var start uint64
+	if i > 0 {
+		start = binary.LittleEndian.Uint64(m.slots[i*mmapSlotSize-8:])
+	}
+	keyEnd := binary.LittleEndian.Uint64(m.slots[i*mmapSlotSize:])
+	valEnd := binary.LittleEndian.Uint64(m.slots[i*mmapSlotSize+8:])
+	if start > keyEnd || keyEnd > valEnd || valEnd > uint64(len(m.data)) {
+		panic(ErrBadMmapFile)
+	}
+	return m.data[start:keyEnd], m.data[keyEnd:valEnd]
+}
+
+func (m *mmapMap[K, V]) search(key K) ([]byte, bool) {
+	var buf [16]byte
+	enc := appendMmapKey(buf[:0], m.kind, key)
+	i := sort.Search(m.count, func(i int) bool {
+		k, _ := m.entry(i)
+		return bytes.Compare(k, enc) >= 0
+	})
+	if i == m.count {
+		return nil, false
+	}
+	k, val := m.entry(i)
+	return val, bytes.Equal(k, enc)
+}
+
+func (m *mmapMap[K, V]) Load(key K) (V, bool) {
+	var zero V
+	b, ok := m.search(key)
+	if !ok {
+		return zero, false
+	}
+	val, _, err := m.vc.Decode(b)
+	if err != nil {
+		panic(err)
+	}
+	return val, true
+}
+
+func (m *mmapMap[K, V]) Contain(key K) bool {
+	_, ok := m.search(key)
+	return ok
+}
+
+func (m *mmapMap[K, V]) Store(key K, val V) {
+	panic(ErrReadOnly)
+}
+
+func (m *mmapMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	panic(ErrReadOnly)
+}
+
+func (m *mmapMap[K, V]) Delete(key K) {
+	panic(ErrReadOnly)
+}
+
+func (m *mmapMap[K, V]) Clear() {
+	panic(ErrReadOnly)
+}
+
+func (m *mmapMap[K, V]) Len() int {
+	return m.count
+}
+
+// Range iterates in the order of the keys
+func (m *mmapMap[K, V]) Range(f func(key K, val V) bool) {
+	for i := 0; i < m.count; i++ {
+		k, b := m.entry(i)
+		val, _, err := m.vc.Decode(b)
+		if err != nil {
+			panic(err)
+		}
+		if !f(decodeMmapKey[K](m.kind, k), val) {
+			return
+		}
+	}
+}
+
+func (m *mmapMap[K, V]) Close() error {
+	if m.file == nil {
+		return nil
+	}
+	err := munmapFile(m.file)
+	m.file, m.slots, m.data, m.count = nil, nil, nil, 0
+	return err
+}
+
+func (m *mmapMap[K, V]) describe() Description {
+	return Description{
+		Backend:      "MmapMap",
+		Capabilities: CapOrdered | CapThreadSafe | CapPersistent | CapReadOnly,
+		Complexity:   Complexity{Load: logarithmicTime, Range: linearTime},
+	}
+}
@@ -0,0 +1,109 @@
+package gomap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMmapMap_IntKeys(t *testing.T) {
+	src := NewPureMap[int, string]()
+	for _, key := range []int{5, -3, 42, 0, -1 << 40, 7} {
+		src.Store(key, "v")
+	}
+	src.Store(42, "answer")
+
+	path := filepath.Join(t.TempDir(), "ints.gmmf")
+	if err := WriteMmapFile[int, string](path, src); err != nil {
+		t.Fatalf("WriteMmapFile: Expected no error, but got %v", err)
+	}
+	m, err := OpenMmapMap[int, string](path)
+	if err != nil {
+		t.Fatalf("OpenMmapMap: Expected no error, but got %v", err)
+	}
+	defer m.Close()
+
+	if val, ok := m.Load(42); !ok || val != "answer" {
+		t.Errorf("Load: Expected answer, but got %v", val)
+	}
+	for _, key := range []int{-1 << 40, -3, 0, 5, 7} {
+		if !m.Contain(key) {
+			t.Errorf("Contain: Expected key %d to exist, but it doesn't", key)
+		}
+	}
+	for _, key := range []int{-2, 1, 100, -1 << 41} {
+		if m.Contain(key) {
+			t.Errorf("Contain: Expected key %d to not exist, but it does", key)
+		}
+	}
+
+	var keys []int
+	m.Range(func(key int, _ string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if !reflect.DeepEqual(keys, []int{-1 << 40, -3, 0, 5, 7, 42}) || m.Len() != 6 {
+		t.Errorf("Range: Expected sorted keys, but got %v", keys)
+	}
+}
+
+func TestMmapMap_StringKeys(t *testing.T) {
+	type word string
+	src := NewSortedSliceMap[word, uint32]()
+	for i, key := range []word{"pear", "apple", "", "apples", "banana"} {
+		src.Store(key, uint32(i))
+	}
+
+	path := filepath.Join(t.TempDir(), "words.gmmf")
+	if err := WriteMmapFile[word, uint32](path, src, WithValueCodec(FixedIntCodec[uint32]())); err != nil {
+		t.Fatalf("WriteMmapFile: Expected no error, but got %v", err)
+	}
+	m, err := OpenMmapMap[word, uint32](path, WithValueCodec(FixedIntCodec[uint32]()))
+	if err != nil {
+		t.Fatalf("OpenMmapMap: Expected no error, but got %v", err)
+	}
+	defer m.Close()
+
+	if got := entriesOf[word, uint32](m); !reflect.DeepEqual(got, entriesOf(src)) {
+		t.Errorf("Range: Expected %v, but got %v", entriesOf(src), got)
+	}
+	if val, ok := m.Load("apples"); !ok || val != 3 {
+		t.Errorf("Load: Expected 3, but got %v", val)
+	}
+	if m.Contain("app") || m.Contain("zebra") {
+		t.Errorf("Contain: Expected the missing keys to not exist")
+	}
+
+	defer func() {
+		if r := recover(); r != ErrReadOnly {
+			t.Errorf("Store: Expected a panic with ErrReadOnly, but got %v", r)
+		}
+	}()
+	m.Store("kiwi", 9)
+}
+
+func TestMmapMap_BadFile(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.gmmf")
+	if err := WriteMmapFile[uint8, string](empty, NewPureMap[uint8, string]()); err != nil {
+		t.Fatalf("WriteMmapFile: Expected no error, but got %v", err)
+	}
+	m, err := OpenMmapMap[uint8, string](empty)
+	if err != nil || m.Len() != 0 || m.Contain(0) {
+		t.Errorf("OpenMmapMap: Expected an empty map, but got %v", err)
+	}
+	m.Close()
+
+	// The keys are not of the type written
+	if _, err := OpenMmapMap[string, string](empty); !errors.Is(err, ErrBadMmapFile) {
+		t.Errorf("OpenMmapMap: Expected ErrBadMmapFile, but got %v", err)
+	}
+
+	garbage := filepath.Join(dir, "garbage.gmmf")
+	os.WriteFile(garbage, []byte("GMMF\x01\x02\x00\x00\xff\xff\xff\xff\x00\x00\x00\x00"), 0o644)
+	if _, err := OpenMmapMap[uint8, string](garbage); !errors.Is(err, ErrBadMmapFile) {
+		t.Errorf("OpenMmapMap: Expected ErrBadMmapFile, but got %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package gomap
+
+import "os"
+
+// mmapFile reads the whole file at path, where mmap is not available
+func mmapFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func munmapFile(b []byte) error {
+	return nil
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package gomap
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile maps the whole file at path read-only
+func mmapFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < mmapHeaderSize || info.Size() != int64(int(info.Size())) {
+		return nil, ErrBadMmapFile
+	}
+	return syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+func munmapFile(b []byte) error {
+	return syscall.Munmap(b)
+}
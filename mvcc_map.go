@@ -0,0 +1,401 @@
+package gomap
+
+import (
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrReadOnly is the panic value of the write methods of a read-only map
+var ErrReadOnly = errors.New("gomap: map is read-only")
+
+// MVCCMap is a thread-safe Map which keeps a version of every write,
+// so a Snapshot can keep reading the map as it was while writers go on
+type MVCCMap[K comparable, V any] interface {
+	Map[K, V]
+	// Version returns the version of the last write
+	Version() uint64
+	// Snapshot returns a read-only view of the map frozen at the current version
+	Snapshot() MVCCSnapshot[K, V]
+	Iterable[K, V]
+}
+
+// MVCCSnapshot is a read-only view of an MVCCMap at a given version.
+// Its write methods panic with ErrReadOnly.
+// Release must be called once the snapshot is not used anymore,
+// so the versions only it needs can be garbage-collected.
+type MVCCSnapshot[K comparable, V any] interface {
+	Map[K, V]
+	Version() uint64
+	Iterable[K, V]
+	Release()
+}
+
+// mvccVersion is a version of the value of a key,
+// linked to the previous (older) version of the same key
+type mvccVersion[V any] struct {
+	ver     uint64
+	val     V
+	deleted bool
+	next    atomic.Pointer[mvccVersion[V]]
+}
+
+// mvccChain holds the versions of a key, newest first
+type mvccChain[V any] struct {
+	head atomic.Pointer[mvccVersion[V]]
+}
+
+// push links n as the newest version
+func (c *mvccChain[V]) push(n *mvccVersion[V]) {
+	n.next.Store(c.head.Load())
+	c.head.Store(n)
+}
+
+// visible returns the newest version not newer than ver
+func (c *mvccChain[V]) visible(ver uint64) *mvccVersion[V] {
+	for n := c.head.Load(); n != nil; n = n.next.Load() {
+		if n.ver <= ver {
+			return n
+		}
+	}
+	return nil
+}
+
+// latestVersion makes the reads of the map itself see the newest versions
+const latestVersion = math.MaxUint64
+
+type mvccMap[K comparable, V any] struct {
+	chains  sync.Map // K -> *mvccChain[V], read without locking
+	version atomic.Uint64
+
+	mu        sync.Mutex     // serializes writers and snapshot bookkeeping
+	snapshots map[uint64]int // version -> number of active snapshots
+}
+
+// NewMVCCMap creates a multi-version map.
+// Readers never take a lock, writers are serialized.
+// thread-safe
+func NewMVCCMap[K comparable, V any](opts ...Option) MVCCMap[K, V] {
+	opt := option{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	return &mvccMap[K, V]{
+		snapshots: make(map[uint64]int),
+	}
+}
+
+func (m *mvccMap[K, V]) Store(key K, val V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.writeLocked(key, val, false)
+}
+
+func (m *mvccMap[K, V]) Load(key K) (V, bool) {
+	return m.loadAt(key, latestVersion)
+}
+
+func (m *mvccMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var zero V
+	val, ok := m.loadAt(key, latestVersion)
+	if ok {
+		m.writeLocked(key, zero, true)
+	}
+	return val, ok
+}
+
+func (m *mvccMap[K, V]) Delete(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Contain(key) {
+		var zero V
+		m.writeLocked(key, zero, true)
+	}
+}
+
+func (m *mvccMap[K, V]) Contain(key K) bool {
+	_, ok := m.Load(key)
+	return ok
+}
+
+func (m *mvccMap[K, V]) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ver := m.version.Load() + 1
+	m.chains.Range(func(_, c any) bool {
+		if !c.(*mvccChain[V]).head.Load().deleted {
+			c.(*mvccChain[V]).push(&mvccVersion[V]{ver: ver, deleted: true})
+		}
+		return true
+	})
+	m.version.Store(ver)
+	m.gcLocked()
+}
+
+func (m *mvccMap[K, V]) Version() uint64 {
+	return m.version.Load()
+}
+
+// Len counts the entries, it is O(n)
+func (m *mvccMap[K, V]) Len() int {
+	n := 0
+	m.Range(func(K, V) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+func (m *mvccMap[K, V]) Range(f func(key K, val V) bool) {
+	m.rangeAt(latestVersion, f)
+}
+
+func (m *mvccMap[K, V]) Snapshot() MVCCSnapshot[K, V] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ver := m.version.Load()
+	m.snapshots[ver]++
+	return &mvccSnapshot[K, V]{m: m, ver: ver}
+}
+
+func (m *mvccMap[K, V]) loadAt(key K, ver uint64) (V, bool) {
+	var zero V
+	c, ok := m.chains.Load(key)
+	if !ok {
+		return zero, false
+	}
+	n := c.(*mvccChain[V]).visible(ver)
+	if n == nil || n.deleted {
+		return zero, false
+	}
+	return n.val, true
+}
+
+func (m *mvccMap[K, V]) rangeAt(ver uint64, f func(key K, val V) bool) {
+	m.chains.Range(func(key, c any) bool {
+		n := c.(*mvccChain[V]).visible(ver)
+		if n == nil || n.deleted {
+			return true
+		}
+		return f(key.(K), n.val)
+	})
+}
+
+// writeLocked adds a new version of key, the caller must hold mu
+func (m *mvccMap[K, V]) writeLocked(key K, val V, deleted bool) {
+	ver := m.version.Load() + 1
+	c, _ := m.chains.LoadOrStore(key, &mvccChain[V]{})
+	c.(*mvccChain[V]).push(&mvccVersion[V]{ver: ver, val: val, deleted: deleted})
+	m.version.Store(ver)
+	m.pruneLocked(key, c.(*mvccChain[V]), m.oldestSnapshotLocked())
+}
+
+// oldestSnapshotLocked returns the version of the oldest active snapshot,
+// or the current version if there is none
+func (m *mvccMap[K, V]) oldestSnapshotLocked() uint64 {
+	oldest := m.version.Load()
+	for ver := range m.snapshots {
+		if ver < oldest {
+			oldest = ver
+		}
+	}
+	return oldest
+}
+
+// pruneLocked unlinks the versions older than the one visible at oldest,
+// and removes the key when only a deletion is left
+func (m *mvccMap[K, V]) pruneLocked(key K, c *mvccChain[V], oldest uint64) {
+	head := c.head.Load()
+	for n := head; n != nil; n = n.next.Load() {
+		if n.ver <= oldest {
+			n.next.Store(nil)
+			break
+		}
+	}
+	if head.deleted && head.next.Load() == nil {
+		m.chains.Delete(key)
+	}
+}
+
+// release forgets a snapshot and garbage-collects the versions it kept alive
+func (m *mvccMap[K, V]) release(ver uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.snapshots[ver]--
+	if m.snapshots[ver] > 0 {
+		return
+	}
+	delete(m.snapshots, ver)
+	m.gcLocked()
+}
+
+// gcLocked prunes the versions of every key
+func (m *mvccMap[K, V]) gcLocked() {
+	oldest := m.oldestSnapshotLocked()
+	m.chains.Range(func(key, c any) bool {
+		m.pruneLocked(key.(K), c.(*mvccChain[V]), oldest)
+		return true
+	})
+}
+
+type mvccSnapshot[K comparable, V any] struct {
+	m        *mvccMap[K, V]
+	ver      uint64
+	released atomic.Bool
+}
+
+func (s *mvccSnapshot[K, V]) Store(key K, val V) {
+	panic(ErrReadOnly)
+}
+
+func (s *mvccSnapshot[K, V]) Load(key K) (V, bool) {
+	return s.m.loadAt(key, s.ver)
+}
+
+func (s *mvccSnapshot[K, V]) LoadAndDelete(key K) (V, bool) {
+	panic(ErrReadOnly)
+}
+
+func (s *mvccSnapshot[K, V]) Delete(key K) {
+	panic(ErrReadOnly)
+}
+
+func (s *mvccSnapshot[K, V]) Contain(key K) bool {
+	_, ok := s.Load(key)
+	return ok
+}
+
+func (s *mvccSnapshot[K, V]) Clear() {
+	panic(ErrReadOnly)
+}
+
+func (s *mvccSnapshot[K, V]) Version() uint64 {
+	return s.ver
+}
+
+// Len counts the entries, it is O(n)
+func (s *mvccSnapshot[K, V]) Len() int {
+	n := 0
+	s.Range(func(K, V) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+func (s *mvccSnapshot[K, V]) Range(f func(key K, val V) bool) {
+	s.m.rangeAt(s.ver, f)
+}
+
+// Release is safe to call more than once
+func (s *mvccSnapshot[K, V]) Release() {
+	if s.released.CompareAndSwap(false, true) {
+		s.m.release(s.ver)
+	}
+}
+
+func (s *mvccSnapshot[K, V]) describe() Description {
+	// A snapshot walks the versions written after it
+	return Description{
+		Backend:      "MVCCSnapshot",
+		Capabilities: CapThreadSafe | CapReadOnly,
+		Complexity:   Complexity{Load: "O(1 + newer versions)", Range: "O(n + newer versions)"},
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *mvccMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (m *mvccMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[K, V](data, m)
+}
+
+func (m *mvccMap[K, V]) backendType() BackendType {
+	return BackendMVCCMap
+}
+
+func (m *mvccMap[K, V]) describe() Description {
+	return Description{Backend: "MVCCMap", Capabilities: CapThreadSafe, Complexity: hashComplexity}
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs.
+// It reads an MVCCSnapshot, so it sees a consistent state of the map without blocking the writers.
+func (m *mvccMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	s := m.Snapshot()
+	defer s.Release()
+	return writeSnapshot[K, V](w, BackendMVCCMap, s, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// loadSnapshot replaces the content with a snapshot.
+// The whole content is written as one version, so an MVCCSnapshot sees the map either before or after it.
+func (m *mvccMap[K, V]) loadSnapshot(r io.Reader, kc Codec[K], vc Codec[V]) (int64, error) {
+	_, _, keys, vals, n, err := readSnapshotEntries(r, kc, vc)
+	if err != nil {
+		return n, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.snapshots == nil {
+		// A zero mvccMap, such as the one gob decodes into
+		m.snapshots = make(map[uint64]int)
+	}
+	loaded := make(map[K]struct{}, len(keys))
+	for _, key := range keys {
+		loaded[key] = struct{}{}
+	}
+	ver := m.version.Load() + 1
+	m.chains.Range(func(key, c any) bool {
+		if _, ok := loaded[key.(K)]; !ok && !c.(*mvccChain[V]).head.Load().deleted {
+			c.(*mvccChain[V]).push(&mvccVersion[V]{ver: ver, deleted: true})
+		}
+		return true
+	})
+	for i, key := range keys {
+		c, _ := m.chains.LoadOrStore(key, &mvccChain[V]{})
+		c.(*mvccChain[V]).push(&mvccVersion[V]{ver: ver, val: vals[i]})
+	}
+	m.version.Store(ver)
+	m.gcLocked()
+	return n, nil
+}
+
+// ReadFrom implements the io.ReaderFrom interface, replacing the content with a snapshot
+func (m *mvccMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	return m.loadSnapshot(r, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, see WriteTo
+func (m *mvccMap[K, V]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, see ReadFrom
+func (m *mvccMap[K, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m)
+}
+
+// GobEncode implements the gob.GobEncoder interface
+func (m *mvccMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface
+func (m *mvccMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
@@ -0,0 +1,172 @@
+package gomap
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func chainLen[K comparable, V any](m MVCCMap[K, V], key K) int {
+	c, ok := m.(*mvccMap[K, V]).chains.Load(key)
+	if !ok {
+		return 0
+	}
+	n := 0
+	for v := c.(*mvccChain[V]).head.Load(); v != nil; v = v.next.Load() {
+		n++
+	}
+	return n
+}
+
+func TestMVCCMap(t *testing.T) {
+	m := NewMVCCMap[int, string]()
+
+	// Test Store and Load methods
+	m.Store(1, "one")
+	val, ok := m.Load(1)
+	if !ok || val != "one" {
+		t.Errorf("Load: Expected value 'one', but got '%s'", val)
+	}
+
+	// Test LoadAndDelete method
+	val, ok = m.LoadAndDelete(1)
+	if !ok || val != "one" {
+		t.Errorf("LoadAndDelete: Expected value 'one', but got '%s'", val)
+	}
+	if m.Contain(1) {
+		t.Errorf("LoadAndDelete: Expected key 1 to be deleted, but it still exists")
+	}
+
+	// Test Delete method
+	m.Store(2, "two")
+	m.Delete(2)
+	if m.Contain(2) {
+		t.Errorf("Delete: Expected key 2 to be deleted, but it still exists")
+	}
+
+	// Test Clear method
+	m.Store(3, "three")
+	m.Clear()
+	if m.Contain(3) {
+		t.Errorf("Clear: Expected map to be empty, but it still contains keys")
+	}
+	if n := chainLen(m, 3); n != 0 {
+		t.Errorf("Clear: Expected no versions left without snapshots, but got %d", n)
+	}
+}
+
+func TestMVCCMap_Snapshot(t *testing.T) {
+	m := NewMVCCMap[int, string]()
+	m.Store(1, "one")
+	m.Store(2, "two")
+
+	s := m.Snapshot()
+	m.Store(1, "uno")
+	m.Delete(2)
+	m.Store(3, "three")
+	m.Clear()
+
+	if val, ok := s.Load(1); !ok || val != "one" {
+		t.Errorf("Snapshot: Expected value 'one', but got '%s'", val)
+	}
+	if !s.Contain(2) {
+		t.Errorf("Snapshot: Expected key 2 to exist, but it doesn't")
+	}
+	if s.Contain(3) {
+		t.Errorf("Snapshot: Expected key 3 not to exist, but it does")
+	}
+	count := 0
+	s.Range(func(key int, val string) bool {
+		count++
+		return true
+	})
+	if count != 2 {
+		t.Errorf("Range: Expected 2 entries, but got %d", count)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != ErrReadOnly {
+				t.Errorf("Store: Expected panic with ErrReadOnly, but got %v", r)
+			}
+		}()
+		s.Store(4, "four")
+	}()
+
+	// The versions are garbage-collected once the snapshot is released
+	if n := chainLen(m, 1); n < 2 {
+		t.Errorf("Snapshot: Expected old versions to be kept, but got %d", n)
+	}
+	s.Release()
+	s.Release()
+	if n := chainLen(m, 1); n != 0 {
+		t.Errorf("Release: Expected versions to be collected, but got %d", n)
+	}
+}
+
+func TestMVCCMap_ReadFrom(t *testing.T) {
+	src := NewMVCCMap[int, string]()
+	src.Store(1, "one")
+	src.Store(2, "two")
+	src.Store(3, "three")
+	var buf bytes.Buffer
+	if _, err := src.(snapshotter).WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: Expected no error, but got %v", err)
+	}
+
+	m := NewMVCCMap[int, string]()
+	m.Store(1, "uno")
+	m.Store(4, "four")
+	before := m.Snapshot()
+	defer before.Release()
+	if _, err := m.(snapshotter).ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom: Expected no error, but got %v", err)
+	}
+
+	// The snapshot is loaded as a single version
+	if ver := m.Version(); ver != before.Version()+1 {
+		t.Errorf("Version: Expected %d, but got %d", before.Version()+1, ver)
+	}
+	if val, ok := before.Load(1); !ok || val != "uno" || !before.Contain(4) || before.Len() != 2 {
+		t.Errorf("Snapshot: Expected the content before ReadFrom, but got %v", entriesOf[int, string](before))
+	}
+	if val, ok := m.Load(1); !ok || val != "one" || m.Contain(4) || m.Len() != 3 {
+		t.Errorf("ReadFrom: Expected the content of the snapshot, but got %v", entriesOf[int, string](m))
+	}
+}
+
+func TestMVCCMap_Concurrent(t *testing.T) {
+	m := NewMVCCMap[int, int]()
+	for i := 0; i < 100; i++ {
+		m.Store(i, 0)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for round := 1; round <= 100; round++ {
+			for i := 0; i < 100; i++ {
+				m.Store(i, round)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for round := 0; round < 100; round++ {
+			s := m.Snapshot()
+			// All the keys are written in a single direction, so a consistent view
+			// never has a key with a smaller value than a later key
+			prev := -1
+			for i := 0; i < 100; i++ {
+				val, _ := s.Load(i)
+				if prev != -1 && val > prev {
+					t.Errorf("Snapshot: Expected a consistent view, but key %d has %d after %d", i, val, prev)
+				}
+				prev = val
+			}
+			s.Release()
+		}
+	}()
+	wg.Wait()
+}
@@ -1,8 +1,14 @@
 package gomap
 
+import (
+	"io"
+	"maps"
+)
+
 // Define the pureMap struct
 type pureMap[K comparable, V any] struct {
-	store map[K]V
+	store  map[K]V
+	shared bool // store is shared with a FrozenMap, so it is copied before the next write
 }
 
 // NewPureMap creates a new pureMap instance
@@ -14,6 +20,7 @@ func NewPureMap[K comparable, V any]() *pureMap[K, V] {
 
 // Store implements the Store method of the Map interface
 func (pm *pureMap[K, V]) Store(key K, val V) {
+	pm.own()
 	pm.store[key] = val
 }
 
@@ -27,6 +34,7 @@ func (pm *pureMap[K, V]) Load(key K) (V, bool) {
 func (pm *pureMap[K, V]) LoadAndDelete(key K) (V, bool) {
 	val, ok := pm.store[key]
 	if ok {
+		pm.own()
 		delete(pm.store, key)
 	}
 	return val, ok
@@ -34,6 +42,7 @@ func (pm *pureMap[K, V]) LoadAndDelete(key K) (V, bool) {
 
 // Delete implements the Delete method of the Map interface
 func (pm *pureMap[K, V]) Delete(key K) {
+	pm.own()
 	delete(pm.store, key)
 }
 
@@ -46,4 +55,87 @@ func (pm *pureMap[K, V]) Contain(key K) bool {
 // Clear implements the Clear method of the Map interface
 func (pm *pureMap[K, V]) Clear() {
 	pm.store = make(map[K]V)
+	pm.shared = false
+}
+
+// Len implements the Len method of the Iterable interface
+func (pm *pureMap[K, V]) Len() int {
+	return len(pm.store)
+}
+
+// Range implements the Range method of the Iterable interface
+func (pm *pureMap[K, V]) Range(f func(key K, val V) bool) {
+	for key, val := range pm.store {
+		if !f(key, val) {
+			return
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (pm *pureMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](pm)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (pm *pureMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[K, V](data, pm)
+}
+
+func (pm *pureMap[K, V]) backendType() BackendType {
+	return BackendPureMap
+}
+
+func (pm *pureMap[K, V]) describe() Description {
+	return Description{Backend: "PureMap", Complexity: hashComplexity}
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (pm *pureMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeSnapshot[K, V](w, BackendPureMap, pm, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// ReadFrom implements the io.ReaderFrom interface, replacing the content with a snapshot
+func (pm *pureMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	store, n, err := readMapSnapshot[K, V](r)
+	if err != nil {
+		return n, err
+	}
+	pm.store = store
+	pm.shared = false
+	return n, nil
+}
+
+// own copies the store if it is shared with a FrozenMap, before it is written
+func (pm *pureMap[K, V]) own() {
+	if pm.shared {
+		pm.store = maps.Clone(pm.store)
+		pm.shared = false
+	}
+}
+
+// freeze shares the store with a FrozenMap
+func (pm *pureMap[K, V]) freeze() FrozenMap[K, V] {
+	pm.shared = true
+	return &frozenHashMap[K, V]{store: pm.store}
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, see WriteTo
+func (pm *pureMap[K, V]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(pm)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, see ReadFrom
+func (pm *pureMap[K, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, pm)
+}
+
+// GobEncode implements the gob.GobEncoder interface
+func (pm *pureMap[K, V]) GobEncode() ([]byte, error) {
+	return pm.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface
+func (pm *pureMap[K, V]) GobDecode(data []byte) error {
+	return pm.UnmarshalBinary(data)
 }
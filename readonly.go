@@ -0,0 +1,238 @@
+package gomap
+
+import (
+	"cmp"
+	"io"
+	"slices"
+
+	"golang.org/x/exp/constraints"
+)
+
+type readOnlyMap[K comparable, V any] struct {
+	m  Map[K, V]
+	it Iterable[K, V]
+}
+
+// ReadOnly returns a view of m which can't write it, while the writes to m remain visible.
+// The view does not hold m, so it can't be asserted back to a Map.
+// m must be Iterable, ReadOnly panics with ErrNotIterable otherwise.
+// thread-safe if m is thread-safe
+func ReadOnly[K comparable, V any](m Map[K, V]) ReadMap[K, V] {
+	it, ok := m.(Iterable[K, V])
+	if !ok {
+		panic(ErrNotIterable)
+	}
+	return &readOnlyMap[K, V]{m: m, it: it}
+}
+
+func (r *readOnlyMap[K, V]) Load(key K) (V, bool) {
+	return r.m.Load(key)
+}
+
+func (r *readOnlyMap[K, V]) Contain(key K) bool {
+	return r.m.Contain(key)
+}
+
+func (r *readOnlyMap[K, V]) Len() int {
+	return r.it.Len()
+}
+
+func (r *readOnlyMap[K, V]) Range(f func(key K, val V) bool) {
+	r.it.Range(f)
+}
+
+func (r *readOnlyMap[K, V]) describe() Description {
+	d := wrapping("ReadOnly", CapReadOnly, CapOrdered|CapThreadSafe|CapFiltered|CapPersistent, Describe(r.m))
+	d.Complexity = readOnlyComplexity(d.Complexity)
+	return d
+}
+
+// freezer is implemented by the backends which share their store with a FrozenMap
+// and copy it on their next write, instead of copying it at once
+type freezer[K comparable, V any] interface {
+	freeze() FrozenMap[K, V]
+}
+
+// FreezeShared returns an immutable FrozenMap of m, whose reads take no lock, without copying where possible.
+// The pure and the sorted slice maps share their store with it, and copy it on their next write only.
+// The other backends are copied by Freeze.
+// A shared FrozenMap writes the snapshots of the backend of m and can't read them back,
+// Freeze builds the FrozenMap which is loaded from its snapshots without rebuilding.
+// m must be Iterable, FreezeShared panics with ErrNotIterable otherwise.
+// thread-safe if m is thread-safe
+func FreezeShared[K comparable, V any](m Map[K, V]) FrozenMap[K, V] {
+	if f, ok := m.(freezer[K, V]); ok {
+		return f.freeze()
+	}
+	return Freeze(m)
+}
+
+// thawer is implemented by the frozen maps which a mutable map can share the store of
+type thawer[K comparable, V any] interface {
+	thaw() Map[K, V]
+}
+
+// Thaw returns a mutable map holding the entries of m.
+// The maps frozen by FreezeShared from a pure or a sorted slice map are thawed into the same backend,
+// which shares the store of m until its first write, the others are copied into a pure map.
+func Thaw[K comparable, V any](m FrozenMap[K, V]) Map[K, V] {
+	if t, ok := m.(thawer[K, V]); ok {
+		return t.thaw()
+	}
+
+	thawed := NewPureMap[K, V]()
+	m.Range(func(key K, val V) bool {
+		thawed.store[key] = val
+		return true
+	})
+	return thawed
+}
+
+// frozenHashMap is the FrozenMap of a pure map, sharing its store
+type frozenHashMap[K comparable, V any] struct {
+	store      map[K]V
+	threadSafe bool // frozen from a threadSafePureMap
+}
+
+func (m *frozenHashMap[K, V]) Load(key K) (V, bool) {
+	val, ok := m.store[key]
+	return val, ok
+}
+
+func (m *frozenHashMap[K, V]) Contain(key K) bool {
+	_, ok := m.store[key]
+	return ok
+}
+
+func (m *frozenHashMap[K, V]) Store(key K, val V) {
+	panic(ErrReadOnly)
+}
+
+func (m *frozenHashMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	panic(ErrReadOnly)
+}
+
+func (m *frozenHashMap[K, V]) Delete(key K) {
+	panic(ErrReadOnly)
+}
+
+func (m *frozenHashMap[K, V]) Clear() {
+	panic(ErrReadOnly)
+}
+
+func (m *frozenHashMap[K, V]) Len() int {
+	return len(m.store)
+}
+
+func (m *frozenHashMap[K, V]) Range(f func(key K, val V) bool) {
+	for key, val := range m.store {
+		if !f(key, val) {
+			return
+		}
+	}
+}
+
+func (m *frozenHashMap[K, V]) thaw() Map[K, V] {
+	if m.threadSafe {
+		return &threadSafePureMap[K, V]{store: m.store, shared: true}
+	}
+	return &pureMap[K, V]{store: m.store, shared: true}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *frozenHashMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](m)
+}
+
+func (m *frozenHashMap[K, V]) backendType() BackendType {
+	return BackendPureMap
+}
+
+func (m *frozenHashMap[K, V]) describe() Description {
+	return Description{Backend: "FrozenPureMap", Capabilities: CapThreadSafe | CapReadOnly, Complexity: readOnlyComplexity(hashComplexity)}
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (m *frozenHashMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeSnapshot[K, V](w, BackendPureMap, m, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// frozenSortedMap is the FrozenMap of a sorted slice map, sharing its store
+type frozenSortedMap[K constraints.Ordered, V any] struct {
+	store      []sliceItem[K, V]
+	threadSafe bool // frozen from a threadSafeSortedSliceMap
+}
+
+func (m *frozenSortedMap[K, V]) Load(key K) (V, bool) {
+	idx, ok := slices.BinarySearchFunc(m.store, key, func(item sliceItem[K, V], key K) int {
+		return cmp.Compare(item.k, key)
+	})
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return m.store[idx].v, true
+}
+
+func (m *frozenSortedMap[K, V]) Contain(key K) bool {
+	_, ok := m.Load(key)
+	return ok
+}
+
+func (m *frozenSortedMap[K, V]) Store(key K, val V) {
+	panic(ErrReadOnly)
+}
+
+func (m *frozenSortedMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	panic(ErrReadOnly)
+}
+
+func (m *frozenSortedMap[K, V]) Delete(key K) {
+	panic(ErrReadOnly)
+}
+
+func (m *frozenSortedMap[K, V]) Clear() {
+	panic(ErrReadOnly)
+}
+
+func (m *frozenSortedMap[K, V]) Len() int {
+	return len(m.store)
+}
+
+// Range iterates in the order of the keys
+func (m *frozenSortedMap[K, V]) Range(f func(key K, val V) bool) {
+	for _, item := range m.store {
+		if !f(item.k, item.v) {
+			return
+		}
+	}
+}
+
+func (m *frozenSortedMap[K, V]) thaw() Map[K, V] {
+	if m.threadSafe {
+		return &threadSafeSortedSliceMap[K, V]{store: m.store, shared: true}
+	}
+	return &sortedSliceMap[K, V]{store: m.store, shared: true}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *frozenSortedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](m)
+}
+
+func (m *frozenSortedMap[K, V]) backendType() BackendType {
+	return BackendSortedSliceMap
+}
+
+func (m *frozenSortedMap[K, V]) describe() Description {
+	return Description{
+		Backend:      "FrozenSortedSliceMap",
+		Capabilities: CapOrdered | CapThreadSafe | CapReadOnly,
+		Complexity:   readOnlyComplexity(sortedComplexity),
+	}
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (m *frozenSortedMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeSnapshot[K, V](w, BackendSortedSliceMap, m, DefaultCodec[K](), DefaultCodec[V]())
+}
@@ -0,0 +1,130 @@
+package gomap
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	m := NewThreadSafeSortedSliceMap[int, string]()
+	m.Store(2, "b")
+	view := ReadOnly(m)
+	m.Store(1, "a")
+
+	if val, ok := view.Load(1); !ok || val != "a" {
+		t.Errorf("Load: Expected the view to see the writes, but got %v", val)
+	}
+	if !view.Contain(2) || view.Len() != 2 {
+		t.Errorf("Contain: Expected 2 entries, but got %d", view.Len())
+	}
+	if _, ok := view.(Map[int, string]); ok {
+		t.Errorf("ReadOnly: Expected the view to not be a Map")
+	}
+	var keys []int
+	view.Range(func(key int, _ string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if !reflect.DeepEqual(keys, []int{1, 2}) {
+		t.Errorf("Range: Expected [1 2], but got %v", keys)
+	}
+}
+
+func TestFreezeShared(t *testing.T) {
+	factories := map[string]func() Map[int, string]{
+		"PureMap":                  func() Map[int, string] { return NewPureMap[int, string]() },
+		"ThreadSafePureMap":        func() Map[int, string] { return NewThreadSafePureMap[int, string]() },
+		"SortedSliceMap":           func() Map[int, string] { return NewSortedSliceMap[int, string]() },
+		"ThreadSafeSortedSliceMap": func() Map[int, string] { return NewThreadSafeSortedSliceMap[int, string]() },
+	}
+	for name, factory := range factories {
+		m := factory()
+		m.Store(1, "a")
+		m.Store(2, "b")
+		m.Store(3, "c")
+
+		frozen := FreezeShared(m)
+		want := map[int]string{1: "a", 2: "b", 3: "c"}
+		m.Store(1, "z")
+		m.Delete(2)
+		m.Store(4, "d")
+		got := map[int]string{}
+		frozen.Range(func(key int, val string) bool {
+			got[key] = val
+			return true
+		})
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: Expected the frozen map to keep %v, but got %v", name, want, got)
+		}
+		if val, _ := m.Load(1); val != "z" || m.Contain(2) || !m.Contain(4) {
+			t.Errorf("%s: Expected the writes after FreezeShared to apply, but got %v", name, entriesOf(m))
+		}
+
+		thawed := Thaw(frozen)
+		if reflect.TypeOf(thawed) != reflect.TypeOf(m) {
+			t.Errorf("%s: Expected Thaw to return a %T, but got %T", name, m, thawed)
+		}
+		thawed.Clear()
+		thawed.Store(5, "e")
+		if frozen.Len() != 3 || frozen.Contain(5) {
+			t.Errorf("%s: Expected the frozen map to not see the writes of the thawed one", name)
+		}
+	}
+
+	// The store is not copied until the next write
+	m := NewSortedSliceMap[int, string]().(*sortedSliceMap[int, string])
+	m.Store(1, "a")
+	frozen := FreezeShared[int, string](m).(*frozenSortedMap[int, string])
+	if &frozen.store[0] != &m.store[0] {
+		t.Errorf("FreezeShared: Expected the store to be shared")
+	}
+	m.Store(1, "b")
+	if &frozen.store[0] == &m.store[0] || frozen.store[0].v != "a" {
+		t.Errorf("Store: Expected the store to be copied before the write")
+	}
+}
+
+func TestFreezeShared_Copied(t *testing.T) {
+	m := NewIntSortedSliceMap[int, string]()
+	m.Store(1, "a")
+	m.Store(2, "b")
+	frozen := FreezeShared(m)
+	m.Delete(1)
+	if !frozen.Contain(1) {
+		t.Errorf("FreezeShared: Expected the frozen map to be a copy")
+	}
+
+	thawed := Thaw(frozen)
+	thawed.Store(3, "c")
+	if got := entriesOf(thawed); len(got) != 3 || frozen.Contain(3) {
+		t.Errorf("Thaw: Expected a mutable copy, but got %v", got)
+	}
+}
+
+func TestFreezeShared_Concurrent(t *testing.T) {
+	m := NewThreadSafePureMap[int, int]()
+	for i := 0; i < 100; i++ {
+		m.Store(i, i)
+	}
+	frozen := FreezeShared(m)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if val, ok := frozen.Load(i % 100); !ok || val != i%100 {
+					t.Errorf("Load: Expected %d, but got %d", i%100, val)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 1000; i++ {
+		m.Store(i%100, -i)
+		m.Delete(i % 7)
+	}
+	wg.Wait()
+}
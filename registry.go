@@ -0,0 +1,328 @@
+package gomap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/constraints"
+)
+
+var (
+	// ErrUnknownBackend is returned when no backend is registered with a name
+	ErrUnknownBackend = errors.New("gomap: unknown backend")
+	// ErrUnsupportedType is returned when a backend has no constructor for the key and value types
+	ErrUnsupportedType = errors.New("gomap: backend does not support the types")
+	// ErrUnbounded is returned when a bounded backend is created without WithCap nor WithMaxCost
+	ErrUnbounded = errors.New("gomap: bounded backend needs WithCap or WithMaxCost")
+)
+
+// Capabilities is a set of features of a backend
+type Capabilities uint32
+
+const (
+	// CapOrdered maps Range in key order
+	CapOrdered Capabilities = 1 << iota
+	// CapThreadSafe maps can be used from several goroutines
+	CapThreadSafe
+	// CapFiltered maps answer most Load of missing keys from a filter, without searching
+	CapFiltered
+	// CapBounded maps evict entries over a capacity or a cost
+	CapBounded
+	// CapPersistent maps keep their entries in files
+	CapPersistent
+	// CapReadOnly maps panic with ErrReadOnly on writes
+	CapReadOnly
+)
+
+var capabilityNames = []struct {
+	c    Capabilities
+	name string
+}{
+	{CapOrdered, "ordered"},
+	{CapThreadSafe, "thread-safe"},
+	{CapFiltered, "filtered"},
+	{CapBounded, "bounded"},
+	{CapPersistent, "persistent"},
+	{CapReadOnly, "read-only"},
+}
+
+// Has tells if c has all the capabilities of o
+func (c Capabilities) Has(o Capabilities) bool {
+	return c&o == o
+}
+
+func (c Capabilities) String() string {
+	var names []string
+	for _, n := range capabilityNames {
+		if c.Has(n.c) {
+			names = append(names, n.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// BackendInfo describes a registered backend
+type BackendInfo struct {
+	Name         string
+	Capabilities Capabilities
+}
+
+type registration struct {
+	caps    Capabilities
+	builtin bool
+	ctors   map[reflect.Type]any // func(opts ...Option) Map[K, V] by its type
+}
+
+var registry = struct {
+	sync.RWMutex
+	backends map[string]*registration
+}{backends: make(map[string]*registration)}
+
+func init() {
+	for _, name := range []string{
+		"PureMap",
+		"ThreadSafePureMap",
+		"SyncMap",
+		"SortedSliceMap",
+		"ThreadSafeSortedSliceMap",
+		"IntSortedSliceMap",
+		"ThreadSafeIntSortedSliceMap",
+		"LinkedMap",
+		"LRUMap",
+		"ThreadSafeLRUMap",
+		"MVCCMap",
+		"AdaptiveMap",
+	} {
+		m, _ := newBuiltin[int, int](name, []Option{WithCap(1)})
+		caps := Describe(m).Capabilities
+		registry.backends[name] = &registration{caps: caps, builtin: true}
+	}
+}
+
+// Register registers the constructor of the backend name for maps of K to V,
+// so New and NewFromConfig can create them.
+// A backend supporting several types registers a constructor per type, with the same capabilities.
+// The built-in backends are registered for the predeclared key types they support,
+// a constructor can be registered for other types, such as a named integer type:
+//
+//	gomap.Register("SortedSliceMap", gomap.CapOrdered, gomap.NewSortedSliceMap[UserID, string])
+//
+// It panics if name is empty, if a constructor is already registered for name, K and V,
+// or if the capabilities differ from the ones name is registered with.
+func Register[K comparable, V any](name string, caps Capabilities, ctor func(opts ...Option) Map[K, V]) {
+	if name == "" || ctor == nil {
+		panic("gomap: Register with an empty name or a nil constructor")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+
+	r, ok := registry.backends[name]
+	if !ok {
+		r = &registration{caps: caps}
+		registry.backends[name] = r
+	}
+	if r.caps != caps {
+		panic(fmt.Sprintf("gomap: Register %s with capabilities %v, registered with %v", name, caps, r.caps))
+	}
+	if r.ctors == nil {
+		r.ctors = make(map[reflect.Type]any)
+	}
+	t := reflect.TypeOf(ctor)
+	if _, dup := r.ctors[t]; dup {
+		panic(fmt.Sprintf("gomap: Register %s called twice for %v", name, t))
+	}
+	r.ctors[t] = ctor
+}
+
+// Backends returns the registered backends sorted by name
+func Backends() []BackendInfo {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	infos := make([]BackendInfo, 0, len(registry.backends))
+	for name, r := range registry.backends {
+		infos = append(infos, BackendInfo{Name: name, Capabilities: r.caps})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// New creates a map of the registered backend name.
+// The bounded built-in backends need WithCap or WithMaxCost, New returns ErrUnbounded otherwise.
+func New[K comparable, V any](name string, opts ...Option) (Map[K, V], error) {
+	registry.RLock()
+	r, ok := registry.backends[name]
+	var ctor any
+	if ok {
+		ctor = r.ctors[reflect.TypeOf((func(...Option) Map[K, V])(nil))]
+	}
+	registry.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownBackend, name)
+	}
+	if ctor != nil {
+		return ctor.(func(...Option) Map[K, V])(opts...), nil
+	}
+	if r.builtin {
+		m, err := newBuiltin[K, V](name, opts)
+		if err != nil || m != nil {
+			return m, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s of %v to %v", ErrUnsupportedType, name,
+		reflect.TypeOf((*K)(nil)).Elem(), reflect.TypeOf((*V)(nil)).Elem())
+}
+
+// newBuiltin creates a built-in backend, or returns nil if it does not support K
+func newBuiltin[K comparable, V any](name string, opts []Option) (Map[K, V], error) {
+	opt := option{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	switch name {
+	case "PureMap":
+		return NewPureMap[K, V](), nil
+	case "ThreadSafePureMap":
+		return NewThreadSafePureMap[K, V](opts...), nil
+	case "SyncMap":
+		return NewSyncMap[K, V](opts...), nil
+	case "LinkedMap":
+		return NewLinkedMap[K, V](opts...), nil
+	case "LRUMap", "ThreadSafeLRUMap":
+		if opt.cap <= 0 && opt.maxCost <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnbounded, name)
+		}
+		if name == "LRUMap" {
+			return NewLRUMap[K, V](opt.cap, opts...), nil
+		}
+		return NewThreadSafeLRUMap[K, V](opt.cap, opts...), nil
+	case "MVCCMap":
+		return NewMVCCMap[K, V](opts...), nil
+	}
+
+	// The other backends need ordered or integer keys, so they are instantiated for the predeclared types
+	var m any
+	switch any(*new(K)).(type) {
+	case int:
+		m = newIntegerBuiltin[int, V](name, opts)
+	case int8:
+		m = newIntegerBuiltin[int8, V](name, opts)
+	case int16:
+		m = newIntegerBuiltin[int16, V](name, opts)
+	case int32:
+		m = newIntegerBuiltin[int32, V](name, opts)
+	case int64:
+		m = newIntegerBuiltin[int64, V](name, opts)
+	case uint:
+		m = newIntegerBuiltin[uint, V](name, opts)
+	case uint8:
+		m = newIntegerBuiltin[uint8, V](name, opts)
+	case uint16:
+		m = newIntegerBuiltin[uint16, V](name, opts)
+	case uint32:
+		m = newIntegerBuiltin[uint32, V](name, opts)
+	case uint64:
+		m = newIntegerBuiltin[uint64, V](name, opts)
+	case uintptr:
+		m = newIntegerBuiltin[uintptr, V](name, opts)
+	case float32:
+		m = newOrderedBuiltin[float32, V](name, opts)
+	case float64:
+		m = newOrderedBuiltin[float64, V](name, opts)
+	case string:
+		m = newOrderedBuiltin[string, V](name, opts)
+	}
+	mk, _ := m.(Map[K, V])
+	return mk, nil
+}
+
+func newIntegerBuiltin[K constraints.Integer, V any](name string, opts []Option) Map[K, V] {
+	switch name {
+	case "IntSortedSliceMap":
+		return NewIntSortedSliceMap[K, V](opts...)
+	case "ThreadSafeIntSortedSliceMap":
+		return NewThreadSafeIntSortedSliceMap[K, V](opts...)
+	}
+	return newOrderedBuiltin[K, V](name, opts)
+}
+
+func newOrderedBuiltin[K constraints.Ordered, V any](name string, opts []Option) Map[K, V] {
+	switch name {
+	case "SortedSliceMap":
+		return NewSortedSliceMap[K, V](opts...)
+	case "ThreadSafeSortedSliceMap":
+		return NewThreadSafeSortedSliceMap[K, V](opts...)
+	case "AdaptiveMap":
+		return NewAdaptiveMap[K, V](opts...)
+	}
+	return nil
+}
+
+// Config is the JSON configuration of a map, read by NewFromConfig:
+//
+//	{"backend": "ThreadSafeLRUMap", "cap": 10000, "shards": 16}
+type Config struct {
+	Backend        string `json:"backend"`
+	Cap            int    `json:"cap,omitempty"`
+	Shards         int    `json:"shards,omitempty"`
+	MaxCost        int64  `json:"max_cost,omitempty"`
+	AccessOrder    bool   `json:"access_order,omitempty"`
+	TxnMode        string `json:"txn_mode,omitempty"` // "pessimistic" or "optimistic"
+	AdaptiveWindow int    `json:"adaptive_window,omitempty"`
+}
+
+// Options returns the options set by c
+func (c Config) Options() ([]Option, error) {
+	var opts []Option
+	if c.Cap != 0 {
+		opts = append(opts, WithCap(c.Cap))
+	}
+	if c.Shards != 0 {
+		opts = append(opts, WithShards(c.Shards))
+	}
+	if c.MaxCost != 0 {
+		opts = append(opts, WithMaxCost(c.MaxCost))
+	}
+	if c.AccessOrder {
+		opts = append(opts, WithAccessOrder())
+	}
+	switch c.TxnMode {
+	case "", "pessimistic":
+	case "optimistic":
+		opts = append(opts, WithTxnMode(Optimistic))
+	default:
+		return nil, fmt.Errorf("gomap: unknown txn_mode %q", c.TxnMode)
+	}
+	if c.AdaptiveWindow != 0 {
+		opts = append(opts, WithAdaptiveWindow(c.AdaptiveWindow))
+	}
+	return opts, nil
+}
+
+// NewFromConfig creates a map from a JSON Config, so the backend can be switched without a code change.
+// opts are applied after the options of the configuration,
+// for the ones which can't be configured in JSON such as WithCostFunc.
+func NewFromConfig[K comparable, V any](data []byte, opts ...Option) (Map[K, V], error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var c Config
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("gomap: config: %w", err)
+	}
+	configured, err := c.Options()
+	if err != nil {
+		return nil, err
+	}
+	return New[K, V](c.Backend, append(configured, opts...)...)
+}
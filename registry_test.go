@@ -0,0 +1,183 @@
+package gomap_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lovung/gomap"
+	"github.com/lovung/gomap/gomaptest"
+)
+
+type (
+	userID  int // registered for SortedSliceMap
+	orderID int // not registered
+)
+
+// countingMap is a third-party backend counting its writes
+type countingMap struct {
+	gomap.Map[string, int]
+	writes int
+}
+
+func (m *countingMap) Store(key string, val int) {
+	m.writes++
+	m.Map.Store(key, val)
+}
+
+func init() {
+	gomap.Register("SortedSliceMap", gomap.CapOrdered, gomap.NewSortedSliceMap[userID, int])
+	gomap.Register("test/CountingMap", 0, func(opts ...gomap.Option) gomap.Map[string, int] {
+		return &countingMap{Map: gomap.NewPureMap[string, int]()}
+	})
+}
+
+func TestNew(t *testing.T) {
+	for _, info := range gomap.Backends() {
+		var mapOpts []gomap.Option
+		if info.Capabilities.Has(gomap.CapBounded) {
+			// Large enough for the conformance suite to never evict
+			mapOpts = append(mapOpts, gomap.WithCap(1<<20))
+		}
+		if _, err := gomap.New[int, string](info.Name, mapOpts...); err != nil {
+			continue // a third-party backend registered for other types
+		}
+		var opts []gomaptest.Option
+		if info.Capabilities.Has(gomap.CapOrdered) {
+			opts = append(opts, gomaptest.Ordered())
+		}
+		if info.Capabilities.Has(gomap.CapThreadSafe) {
+			opts = append(opts, gomaptest.ThreadSafe())
+		}
+		t.Run(info.Name, func(t *testing.T) {
+			gomaptest.RunConformance(t, func() gomap.Map[int, string] {
+				m, err := gomap.New[int, string](info.Name, mapOpts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return m
+			}, opts...)
+		})
+	}
+}
+
+func TestNew_Types(t *testing.T) {
+	if _, err := gomap.New[string, int]("SortedSliceMap"); err != nil {
+		t.Errorf("Expected a sorted map of string keys, but got %v", err)
+	}
+	if _, err := gomap.New[uint16, int]("ThreadSafeIntSortedSliceMap"); err != nil {
+		t.Errorf("Expected a filtered map of uint16 keys, but got %v", err)
+	}
+	if _, err := gomap.New[string, int]("IntSortedSliceMap"); !errors.Is(err, gomap.ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType, but got %v", err)
+	}
+	if _, err := gomap.New[[2]int, int]("PureMap"); err != nil {
+		t.Errorf("Expected a hash map of array keys, but got %v", err)
+	}
+	if _, err := gomap.New[orderID, int]("SortedSliceMap"); !errors.Is(err, gomap.ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType, but got %v", err)
+	}
+	m, err := gomap.New[userID, int]("SortedSliceMap")
+	if err != nil {
+		t.Fatalf("Expected the registered constructor, but got %v", err)
+	}
+	m.Store(1, 1)
+	if !m.Contain(1) {
+		t.Errorf("Expected 1 to be stored")
+	}
+	for _, name := range []string{"LRUMap", "ThreadSafeLRUMap"} {
+		if _, err := gomap.New[int, int](name); !errors.Is(err, gomap.ErrUnbounded) {
+			t.Errorf("%s: Expected ErrUnbounded without a bound, but got %v", name, err)
+		}
+		if m, err := gomap.New[int, int](name, gomap.WithMaxCost(10)); err != nil || m.(gomap.BoundedMap[int, int]).MaxCost() != 10 {
+			t.Errorf("%s: Expected a map bounded by cost, but got %v", name, err)
+		}
+	}
+	if _, err := gomap.New[int, int]("NoSuchMap"); !errors.Is(err, gomap.ErrUnknownBackend) {
+		t.Errorf("Expected ErrUnknownBackend, but got %v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	found := false
+	for _, info := range gomap.Backends() {
+		found = found || info == gomap.BackendInfo{Name: "test/CountingMap"}
+	}
+	if !found {
+		t.Errorf("Expected test/CountingMap in %v", gomap.Backends())
+	}
+	m, err := gomap.NewFromConfig[string, int]([]byte(`{"backend": "test/CountingMap"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Store("a", 1)
+	if got := m.(*countingMap).writes; got != 1 {
+		t.Errorf("Expected 1 write, but got %d", got)
+	}
+	if _, err := gomap.New[int, int]("test/CountingMap"); !errors.Is(err, gomap.ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType, but got %v", err)
+	}
+
+	expectPanic := func(name string, register func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: Expected a panic", name)
+			}
+		}()
+		register()
+	}
+	expectPanic("duplicate", func() {
+		gomap.Register("test/CountingMap", 0, func(opts ...gomap.Option) gomap.Map[string, int] { return nil })
+	})
+	expectPanic("capabilities", func() {
+		gomap.Register("test/CountingMap", gomap.CapOrdered, gomap.NewSortedSliceMap[string, string])
+	})
+	expectPanic("nil", func() {
+		gomap.Register[int, int]("test/Nil", 0, nil)
+	})
+}
+
+func TestNewFromConfig(t *testing.T) {
+	m, err := gomap.NewFromConfig[int, string]([]byte(`{"backend": "ThreadSafeLRUMap", "cap": 2, "shards": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Store(1, "a")
+	m.Store(2, "b")
+	m.Store(3, "c")
+	if m.Contain(1) || !m.Contain(3) {
+		t.Errorf("Expected the LRU entry to be evicted over the cap of 2")
+	}
+
+	m, err = gomap.NewFromConfig[int, string]([]byte(`{"backend": "ThreadSafeSortedSliceMap", "txn_mode": "optimistic"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.(gomap.Transactional[int, string]); !ok {
+		t.Errorf("Expected a Transactional map, but got %T", m)
+	}
+
+	for _, config := range []string{
+		`{"backend": "ThreadSafePureMap", "capacity": 10}`,
+		`{"backend": "ThreadSafePureMap", "txn_mode": "eventual"}`,
+		`{"backend": "NoSuchMap"}`,
+		`{"backend": 1}`,
+	} {
+		if _, err := gomap.NewFromConfig[int, string]([]byte(config)); err == nil {
+			t.Errorf("%s: Expected an error", config)
+		}
+	}
+}
+
+func TestCapabilities_String(t *testing.T) {
+	tests := map[gomap.Capabilities]string{
+		0:                                      "none",
+		gomap.CapOrdered:                       "ordered",
+		gomap.CapThreadSafe | gomap.CapBounded: "thread-safe|bounded",
+	}
+	for c, want := range tests {
+		if got := c.String(); got != want {
+			t.Errorf("Expected %s, but got %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package gomap
+
+// s3fifoMaxFreq caps the access counters of S3-FIFO
+const s3fifoMaxFreq = 3
+
+type s3fifoPolicy[K comparable] struct {
+	smallCap int
+	ghostCap int
+
+	small *keyList[K] // FIFO of the new keys
+	main  *keyList[K] // FIFO of the keys used again while in small
+	ghost *keyList[K] // FIFO of the keys recently evicted from small
+	freqs map[K]int
+}
+
+// NewS3FIFOPolicy evicts with the S3-FIFO algorithm,
+// which filters out the keys used only once with a small FIFO queue
+// so scans don't flush the frequently used keys.
+// capacity must be the capacity of the map.
+func NewS3FIFOPolicy[K comparable](capacity int) EvictionPolicy[K] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &s3fifoPolicy[K]{
+		smallCap: max(capacity/10, 1),
+		ghostCap: capacity,
+		small:    newKeyList[K](),
+		main:     newKeyList[K](),
+		ghost:    newKeyList[K](),
+		freqs:    make(map[K]int),
+	}
+}
+
+func (p *s3fifoPolicy[K]) Access(key K) {
+	if freq, ok := p.freqs[key]; ok && freq < s3fifoMaxFreq {
+		p.freqs[key] = freq + 1
+	}
+}
+
+func (p *s3fifoPolicy[K]) Insert(key K) {
+	p.freqs[key] = 0
+	if p.ghost.Remove(key) {
+		p.main.PushFront(key)
+		return
+	}
+	p.small.PushFront(key)
+}
+
+func (p *s3fifoPolicy[K]) Remove(key K) {
+	if _, ok := p.freqs[key]; !ok {
+		return
+	}
+	delete(p.freqs, key)
+	if !p.small.Remove(key) {
+		p.main.Remove(key)
+	}
+}
+
+func (p *s3fifoPolicy[K]) Victim() (K, bool) {
+	for p.small.Len() > 0 || p.main.Len() > 0 {
+		if p.small.Len() >= p.smallCap || p.main.Len() == 0 {
+			key, _ := p.small.PopBack()
+			if p.freqs[key] > 0 {
+				// Used again while in small, give it a place in main
+				p.freqs[key] = 0
+				p.main.PushFront(key)
+				continue
+			}
+			delete(p.freqs, key)
+			p.ghost.PushFront(key)
+			if p.ghost.Len() > p.ghostCap {
+				p.ghost.PopBack()
+			}
+			return key, true
+		}
+
+		key, _ := p.main.PopBack()
+		if freq := p.freqs[key]; freq > 0 {
+			p.freqs[key] = freq - 1
+			p.main.PushFront(key)
+			continue
+		}
+		delete(p.freqs, key)
+		return key, true
+	}
+
+	var zero K
+	return zero, false
+}
+
+func (p *s3fifoPolicy[K]) Reset() {
+	p.small.Reset()
+	p.main.Reset()
+	p.ghost.Reset()
+	p.freqs = make(map[K]int)
+}
@@ -0,0 +1,404 @@
+package gomap
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"slices"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Snapshot format, all the integers are little-endian or unsigned varints:
+//
+//	magic   "GMAP"
+//	version 1 byte
+//	backend 1 byte, the BackendType of the map written
+//	count   uvarint, the number of entries
+//	blocks  until count entries are read:
+//	    entries uvarint
+//	    size    uvarint
+//	    payload size bytes of entries key/value pairs
+//	    crc     4 bytes, CRC32C of payload
+//	index   version 2 only, a structure to load the entries without rebuilding it:
+//	    size    uvarint
+//	    payload size bytes, specific to the backend
+//	    crc     4 bytes, CRC32C of payload
+//
+// The entries are written in the order of Range, so the sorted maps write sorted keys.
+// Any backend can read an index, which only the backend which wrote it uses.
+
+const (
+	snapshotMagic   = "GMAP"
+	snapshotVersion = 1
+	// snapshotIndexVersion is the version of the snapshots followed by an index
+	snapshotIndexVersion = 2
+
+	// snapshotBlockSize is the payload size after which a block is closed
+	snapshotBlockSize = 64 << 10
+)
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	// ErrBadSnapshot is returned when the data is not a snapshot of a supported version
+	ErrBadSnapshot = errors.New("gomap: not a snapshot")
+	// ErrChecksum is returned when a block of a snapshot is corrupted
+	ErrChecksum = errors.New("gomap: snapshot checksum mismatch")
+)
+
+// BackendType identifies the backend which wrote a snapshot
+type BackendType uint8
+
+const (
+	BackendUnknown BackendType = iota
+	BackendPureMap
+	BackendThreadSafePureMap
+	BackendSyncMap
+	BackendSortedSliceMap
+	BackendThreadSafeSortedSliceMap
+	BackendIntSortedSliceMap
+	BackendThreadSafeIntSortedSliceMap
+	BackendLinkedMap
+	BackendLRUMap
+	BackendThreadSafeLRUMap
+	BackendMVCCMap
+	BackendFrozenMap
+)
+
+var backendNames = [...]string{
+	BackendUnknown:                     "Unknown",
+	BackendPureMap:                     "PureMap",
+	BackendThreadSafePureMap:           "ThreadSafePureMap",
+	BackendSyncMap:                     "SyncMap",
+	BackendSortedSliceMap:              "SortedSliceMap",
+	BackendThreadSafeSortedSliceMap:    "ThreadSafeSortedSliceMap",
+	BackendIntSortedSliceMap:           "IntSortedSliceMap",
+	BackendThreadSafeIntSortedSliceMap: "ThreadSafeIntSortedSliceMap",
+	BackendLinkedMap:                   "LinkedMap",
+	BackendLRUMap:                      "LRUMap",
+	BackendThreadSafeLRUMap:            "ThreadSafeLRUMap",
+	BackendMVCCMap:                     "MVCCMap",
+	BackendFrozenMap:                   "FrozenMap",
+}
+
+func (t BackendType) String() string {
+	if int(t) < len(backendNames) {
+		return backendNames[t]
+	}
+	return fmt.Sprintf("BackendType(%d)", t)
+}
+
+// typedBackend is implemented by the in-tree backends to tag their snapshots
+type typedBackend interface {
+	backendType() BackendType
+}
+
+// indexedBackend is implemented by the backends writing an index after their entries
+type indexedBackend interface {
+	snapshotIndex() []byte
+}
+
+// snapshotLoader is implemented by the backends which load a snapshot by themselves,
+// such as the read-only ones
+type snapshotLoader[K comparable, V any] interface {
+	loadSnapshot(r io.Reader, kc Codec[K], vc Codec[V]) (int64, error)
+}
+
+// SnapshotHeader is the header of a snapshot
+type SnapshotHeader struct {
+	Version uint8
+	Backend BackendType
+	Count   uint64
+}
+
+// WriteSnapshot writes the entries of m to w, encoded with kc and vc.
+// m must be Iterable.
+func WriteSnapshot[K comparable, V any](w io.Writer, m Map[K, V], kc Codec[K], vc Codec[V]) (int64, error) {
+	it, ok := m.(Iterable[K, V])
+	if !ok {
+		return 0, ErrNotIterable
+	}
+	backend := BackendUnknown
+	if tb, ok := m.(typedBackend); ok {
+		backend = tb.backendType()
+	}
+	var index []byte
+	if ib, ok := m.(indexedBackend); ok {
+		index = ib.snapshotIndex()
+	}
+	return writeIndexedSnapshot(w, backend, it, kc, vc, index)
+}
+
+// writeSnapshot writes the entries of it,
+// the thread-safe maps hold their read lock during Range so the snapshot is consistent
+func writeSnapshot[K comparable, V any](w io.Writer, backend BackendType, it Iterable[K, V], kc Codec[K], vc Codec[V]) (int64, error) {
+	return writeIndexedSnapshot(w, backend, it, kc, vc, nil)
+}
+
+// writeIndexedSnapshot writes the entries of it followed by index, if it is not nil
+func writeIndexedSnapshot[K comparable, V any](w io.Writer, backend BackendType, it Iterable[K, V], kc Codec[K], vc Codec[V], index []byte) (int64, error) {
+	cw := &countingWriter{w: w}
+
+	// Encode everything in a single Range before writing the header,
+	// so the count matches the entries even if the map is written concurrently
+	var (
+		entries  []byte
+		n, total uint64
+		err      error
+		blocks   [][]byte
+		counts   []uint64
+	)
+	it.Range(func(key K, val V) bool {
+		if entries, err = kc.Append(entries, key); err != nil {
+			return false
+		}
+		if entries, err = vc.Append(entries, val); err != nil {
+			return false
+		}
+		n++
+		total++
+		if len(entries) >= snapshotBlockSize {
+			blocks = append(blocks, entries)
+			counts = append(counts, n)
+			entries, n = nil, 0
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		blocks = append(blocks, entries)
+		counts = append(counts, n)
+	}
+
+	version := byte(snapshotVersion)
+	if index != nil {
+		version = snapshotIndexVersion
+		blocks = append(blocks, index)
+	}
+	header := append([]byte(snapshotMagic), version, byte(backend))
+	header = binary.AppendUvarint(header, total)
+	if _, err := cw.Write(header); err != nil {
+		return cw.n, err
+	}
+	for i, block := range blocks {
+		var buf []byte
+		if i < len(counts) {
+			buf = binary.AppendUvarint(buf, counts[i])
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(block)))
+		if _, err := cw.Write(buf); err != nil {
+			return cw.n, err
+		}
+		if _, err := cw.Write(block); err != nil {
+			return cw.n, err
+		}
+		if _, err := cw.Write(binary.LittleEndian.AppendUint32(nil, crc32.Checksum(block, crc32c))); err != nil {
+			return cw.n, err
+		}
+	}
+	return cw.n, nil
+}
+
+// ReadSnapshot clears m then stores the entries read from r, decoded with kc and vc.
+// It reads exactly the snapshot, so more data can follow it in r.
+func ReadSnapshot[K comparable, V any](r io.Reader, m Map[K, V], kc Codec[K], vc Codec[V]) (int64, error) {
+	if l, ok := m.(snapshotLoader[K, V]); ok {
+		return l.loadSnapshot(r, kc, vc)
+	}
+	m.Clear()
+	_, _, n, err := readSnapshot(r, kc, vc, func(key K, val V) {
+		m.Store(key, val)
+	})
+	return n, err
+}
+
+// ReadSnapshotHeader reads the header of a snapshot
+func ReadSnapshotHeader(r io.Reader) (SnapshotHeader, error) {
+	h, err := readSnapshotHeader(&countingReader{r: r})
+	return h, err
+}
+
+func readSnapshotHeader(cr *countingReader) (SnapshotHeader, error) {
+	buf := make([]byte, len(snapshotMagic)+2)
+	if _, err := io.ReadFull(cr, buf); err != nil {
+		return SnapshotHeader{}, err
+	}
+	if version := buf[len(snapshotMagic)]; string(buf[:len(snapshotMagic)]) != snapshotMagic ||
+		(version != snapshotVersion && version != snapshotIndexVersion) {
+		return SnapshotHeader{}, ErrBadSnapshot
+	}
+	count, err := binary.ReadUvarint(cr)
+	if err != nil {
+		return SnapshotHeader{}, err
+	}
+	return SnapshotHeader{
+		Version: buf[len(snapshotMagic)],
+		Backend: BackendType(buf[len(snapshotMagic)+1]),
+		Count:   count,
+	}, nil
+}
+
+// readSnapshot reads a snapshot and calls store for every entry in order,
+// then returns its index, which is nil if it has none
+func readSnapshot[K comparable, V any](r io.Reader, kc Codec[K], vc Codec[V], store func(key K, val V)) (SnapshotHeader, []byte, int64, error) {
+	cr := &countingReader{r: r}
+	h, err := readSnapshotHeader(cr)
+	if err != nil {
+		return h, nil, cr.n, err
+	}
+
+	for read := uint64(0); read < h.Count; {
+		entries, err := binary.ReadUvarint(cr)
+		if err != nil {
+			return h, nil, cr.n, err
+		}
+		if entries == 0 || read+entries > h.Count {
+			return h, nil, cr.n, ErrBadSnapshot
+		}
+		payload, err := readSnapshotBlock(cr)
+		if err != nil {
+			return h, nil, cr.n, err
+		}
+
+		for i := uint64(0); i < entries; i++ {
+			key, n, err := kc.Decode(payload)
+			if err != nil {
+				return h, nil, cr.n, fmt.Errorf("gomap: decode key: %w", err)
+			}
+			payload = payload[n:]
+			val, n, err := vc.Decode(payload)
+			if err != nil {
+				return h, nil, cr.n, fmt.Errorf("gomap: decode value: %w", err)
+			}
+			payload = payload[n:]
+			store(key, val)
+		}
+		if len(payload) != 0 {
+			return h, nil, cr.n, ErrBadSnapshot
+		}
+		read += entries
+	}
+
+	if h.Version != snapshotIndexVersion {
+		return h, nil, cr.n, nil
+	}
+	index, err := readSnapshotBlock(cr)
+	return h, index, cr.n, err
+}
+
+// readSnapshotEntries reads a snapshot and returns its entries in order, and its index
+func readSnapshotEntries[K comparable, V any](r io.Reader, kc Codec[K], vc Codec[V]) (SnapshotHeader, []byte, []K, []V, int64, error) {
+	var (
+		keys []K
+		vals []V
+	)
+	h, index, n, err := readSnapshot(r, kc, vc, func(key K, val V) {
+		keys = append(keys, key)
+		vals = append(vals, val)
+	})
+	return h, index, keys, vals, n, err
+}
+
+// readSnapshotBlock reads the size, the payload and the checksum of a block
+func readSnapshotBlock(cr *countingReader) ([]byte, error) {
+	size, err := binary.ReadUvarint(cr)
+	if err != nil {
+		return nil, err
+	}
+	if size > 1<<32 {
+		return nil, ErrBadSnapshot
+	}
+	// A corrupted size must not allocate more than the input holds,
+	// so the block grows as it is read
+	block := make([]byte, 0, min(size+4, snapshotBlockSize))
+	for uint64(len(block)) < size+4 {
+		chunk := int(min(size+4-uint64(len(block)), snapshotBlockSize))
+		block = slices.Grow(block, chunk)
+		n, err := io.ReadFull(cr, block[len(block):len(block)+chunk])
+		block = block[:len(block)+n]
+		if err == io.EOF && len(block) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	payload := block[:size]
+	if crc32.Checksum(payload, crc32c) != binary.LittleEndian.Uint32(block[size:]) {
+		return nil, ErrChecksum
+	}
+	return payload, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// countingReader reads exactly what is asked, so it never consumes data after a snapshot
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+func (cr *countingReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(cr, b[:])
+	return b[0], err
+}
+
+// readMapSnapshot reads a snapshot written with the default codecs into a new map
+func readMapSnapshot[K comparable, V any](r io.Reader) (map[K]V, int64, error) {
+	store := make(map[K]V)
+	_, _, n, err := readSnapshot(r, DefaultCodec[K](), DefaultCodec[V](), func(key K, val V) {
+		store[key] = val
+	})
+	return store, n, err
+}
+
+// readSortedSnapshot reads a snapshot written with the default codecs into a slice sorted by key.
+// It is O(n) for a snapshot of a sorted map, other snapshots are sorted after reading.
+func readSortedSnapshot[K constraints.Ordered, V any](r io.Reader) ([]sliceItem[K, V], int64, error) {
+	items := make([]sliceItem[K, V], 0)
+	sorted := true
+	_, _, n, err := readSnapshot(r, DefaultCodec[K](), DefaultCodec[V](), func(key K, val V) {
+		if len(items) > 0 && items[len(items)-1].k >= key {
+			sorted = false
+		}
+		items = append(items, sliceItem[K, V]{key, val})
+	})
+	if err != nil || sorted {
+		return items, n, err
+	}
+
+	slices.SortStableFunc(items, func(a, b sliceItem[K, V]) int {
+		return cmp.Compare(a.k, b.k)
+	})
+	// Keep the last value of a duplicated key, like Store would
+	deduped := items[:0]
+	for i, item := range items {
+		if i+1 < len(items) && items[i+1].k == item.k {
+			continue
+		}
+		deduped = append(deduped, item)
+	}
+	return deduped, n, nil
+}
@@ -0,0 +1,179 @@
+package gomap
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+type snapshotter interface {
+	io.WriterTo
+	io.ReaderFrom
+}
+
+func TestSnapshot_Backends(t *testing.T) {
+	factories := map[string]func() Map[int, string]{
+		"PureMap":                     func() Map[int, string] { return NewPureMap[int, string]() },
+		"ThreadSafePureMap":           func() Map[int, string] { return NewThreadSafePureMap[int, string]() },
+		"SyncMap":                     func() Map[int, string] { return NewSyncMap[int, string]() },
+		"SortedSliceMap":              func() Map[int, string] { return NewSortedSliceMap[int, string]() },
+		"ThreadSafeSortedSliceMap":    func() Map[int, string] { return NewThreadSafeSortedSliceMap[int, string]() },
+		"IntSortedSliceMap":           func() Map[int, string] { return NewIntSortedSliceMap[int, string]() },
+		"ThreadSafeIntSortedSliceMap": func() Map[int, string] { return NewThreadSafeIntSortedSliceMap[int, string]() },
+		"LinkedMap":                   func() Map[int, string] { return NewLinkedMap[int, string]() },
+		"LRUMap":                      func() Map[int, string] { return NewLRUMap[int, string](100) },
+		"ThreadSafeLRUMap":            func() Map[int, string] { return NewThreadSafeLRUMap[int, string](100) },
+		"MVCCMap":                     func() Map[int, string] { return NewMVCCMap[int, string]() },
+	}
+	for name, factory := range factories {
+		m := factory()
+		for _, key := range []int{5, -3, 42, 7} {
+			m.Store(key, "v")
+		}
+
+		var buf bytes.Buffer
+		written, err := m.(snapshotter).WriteTo(&buf)
+		if err != nil || written != int64(buf.Len()) {
+			t.Errorf("%s: Expected %d bytes written, but got %d and %v", name, buf.Len(), written, err)
+		}
+
+		header, err := ReadSnapshotHeader(bytes.NewReader(buf.Bytes()))
+		if err != nil || header.Count != 4 || header.Backend != m.(typedBackend).backendType() {
+			t.Errorf("%s: Expected a header of 4 entries, but got %+v and %v", name, header, err)
+		}
+
+		decoded := factory()
+		decoded.Store(100, "stale")
+		read, err := decoded.(snapshotter).ReadFrom(&buf)
+		if err != nil || read != written {
+			t.Errorf("%s: Expected %d bytes read, but got %d and %v", name, written, read, err)
+		}
+		if decoded.Contain(100) || decoded.(Iterable[int, string]).Len() != 4 {
+			t.Errorf("%s: Expected the snapshot to replace the content", name)
+		}
+		for _, key := range []int{5, -3, 42, 7} {
+			if !decoded.Contain(key) {
+				t.Errorf("%s: Expected key %d to exist, but it doesn't", name, key)
+			}
+		}
+	}
+}
+
+func TestSnapshot_SortedLoad(t *testing.T) {
+	// A snapshot of an unsorted map is sorted when loaded by a sorted map
+	src := NewLinkedMap[int, string]()
+	for _, key := range []int{3, 1, 2, 1} {
+		src.Store(key, "v")
+	}
+	var buf bytes.Buffer
+	if _, err := src.(snapshotter).WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: Expected no error, but got %v", err)
+	}
+
+	m := NewSortedSliceMap[int, string]()
+	if _, err := m.(snapshotter).ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom: Expected no error, but got %v", err)
+	}
+	if keys := entriesOf(m); !reflect.DeepEqual(keys, []entry[int, string]{{1, "v"}, {2, "v"}, {3, "v"}}) {
+		t.Errorf("ReadFrom: Expected sorted keys, but got %v", keys)
+	}
+}
+
+func TestSnapshot_Codecs(t *testing.T) {
+	type point struct{ X, Y int }
+	type name string
+
+	m := NewPureMap[name, point]()
+	m.Store("a", point{1, 2})
+	m.Store("b", point{3, 4})
+
+	var buf bytes.Buffer
+	if _, err := WriteSnapshot[name, point](&buf, m, StringCodec[name](), GobCodec[point]()); err != nil {
+		t.Fatalf("WriteSnapshot: Expected no error, but got %v", err)
+	}
+	buf.WriteString("trailing")
+
+	decoded := NewPureMap[name, point]()
+	if _, err := ReadSnapshot[name, point](&buf, decoded, StringCodec[name](), GobCodec[point]()); err != nil {
+		t.Fatalf("ReadSnapshot: Expected no error, but got %v", err)
+	}
+	if val, _ := decoded.Load("b"); val != (point{3, 4}) {
+		t.Errorf("ReadSnapshot: Expected {3 4}, but got %v", val)
+	}
+	if buf.String() != "trailing" {
+		t.Errorf("ReadSnapshot: Expected the data after the snapshot to be left, but got %q", buf.String())
+	}
+
+	// The default codec handles named types by their kind
+	ids := NewSortedSliceMap[name, float64]()
+	ids.Store("pi", 3.14)
+	buf.Reset()
+	if _, err := ids.(snapshotter).WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: Expected no error, but got %v", err)
+	}
+	decodedIDs := NewSortedSliceMap[name, float64]()
+	if _, err := decodedIDs.(snapshotter).ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom: Expected no error, but got %v", err)
+	}
+	if val, _ := decodedIDs.Load("pi"); val != 3.14 {
+		t.Errorf("ReadFrom: Expected 3.14, but got %v", val)
+	}
+
+	for _, c := range []Codec[int64]{FixedIntCodec[int64](), VarintCodec[int64](), DefaultCodec[int64]()} {
+		buf, _ := c.Append(nil, -1<<40)
+		if v, n, err := c.Decode(buf); v != -1<<40 || n != len(buf) || err != nil {
+			t.Errorf("%T: Expected a round trip, but got %d, %d and %v", c, v, n, err)
+		}
+		if _, _, err := c.Decode(buf[:len(buf)-1]); !errors.Is(err, ErrShortBuffer) {
+			t.Errorf("%T: Expected ErrShortBuffer, but got %v", c, err)
+		}
+	}
+}
+
+func TestSnapshot_Corruption(t *testing.T) {
+	m := NewSortedSliceMap[int, string]()
+	// Enough entries for several blocks
+	for i := 0; i < 20000; i++ {
+		m.Store(i, "some value")
+	}
+	var buf bytes.Buffer
+	if _, err := m.(snapshotter).WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: Expected no error, but got %v", err)
+	}
+	data := buf.Bytes()
+
+	decoded := NewSortedSliceMap[int, string]()
+	if _, err := decoded.(snapshotter).ReadFrom(bytes.NewReader(data)); err != nil || decoded.(Iterable[int, string]).Len() != 20000 {
+		t.Errorf("ReadFrom: Expected 20000 entries, but got %v", err)
+	}
+
+	corrupted := bytes.Clone(data)
+	corrupted[len(corrupted)/2] ^= 0xff
+	if _, err := decoded.(snapshotter).ReadFrom(bytes.NewReader(corrupted)); !errors.Is(err, ErrChecksum) {
+		t.Errorf("ReadFrom: Expected ErrChecksum, but got %v", err)
+	}
+	if _, err := decoded.(snapshotter).ReadFrom(bytes.NewReader(data[:len(data)-1])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadFrom: Expected io.ErrUnexpectedEOF, but got %v", err)
+	}
+	if _, err := decoded.(snapshotter).ReadFrom(bytes.NewReader([]byte("JSON{}"))); !errors.Is(err, ErrBadSnapshot) {
+		t.Errorf("ReadFrom: Expected ErrBadSnapshot, but got %v", err)
+	}
+}
+
+func TestSnapshot_BlockSize(t *testing.T) {
+	// A corrupted size of about 4 GiB before a few bytes of input
+	data := []byte{0x80, 0x80, 0x80, 0x80, 0x0f, 1, 2, 3}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := readSnapshotBlock(&countingReader{r: bytes.NewReader(data)})
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("readSnapshotBlock: Expected io.ErrUnexpectedEOF, but got %v", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("readSnapshotBlock: Expected to allocate less than 1 MiB, but allocated %d bytes", allocated)
+	}
+}
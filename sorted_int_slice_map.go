@@ -1,6 +1,8 @@
 package gomap
 
 import (
+	"io"
+
 	bf "github.com/lovung/bloomfilter"
 	"golang.org/x/exp/constraints"
 )
@@ -33,6 +35,12 @@ func NewIntSortedSliceMap[K constraints.Integer, V any](opts ...Option) Map[K, V
 	return m
 }
 
+// mayExist reports whether key may be in a map whose keys were added to f.
+// The filter ORs the bits of the keys, so it can't tell anything about the key 0.
+func mayExist[K constraints.Integer](f bf.BloomFilter[K], key K) bool {
+	return key == 0 || f.MayExist(key)
+}
+
 // return left_index and exist
 // E.g.
 // Current data: []int{1, 3},
@@ -76,7 +84,7 @@ func (m *intSortedSliceMap[K, V]) Store(key K, val V) {
 
 func (m *intSortedSliceMap[K, V]) Load(key K) (V, bool) {
 	var zero V
-	if !m.bloomFilter.MayExist(key) {
+	if !mayExist(m.bloomFilter, key) {
 		return zero, false
 	}
 	idx, exist := m.binarySearch(key)
@@ -105,7 +113,7 @@ func (m *intSortedSliceMap[K, V]) Delete(key K) {
 }
 
 func (m *intSortedSliceMap[K, V]) Contain(key K) bool {
-	if !m.bloomFilter.MayExist(key) {
+	if !mayExist(m.bloomFilter, key) {
 		return false
 	}
 	_, exist := m.binarySearch(key)
@@ -114,4 +122,78 @@ func (m *intSortedSliceMap[K, V]) Contain(key K) bool {
 
 func (m *intSortedSliceMap[K, V]) Clear() {
 	m.store = make([]intSliceItem[K, V], 0)
+	m.bloomFilter = bf.BloomFilter[K](0)
+}
+
+func (m *intSortedSliceMap[K, V]) Len() int {
+	return len(m.store)
+}
+
+func (m *intSortedSliceMap[K, V]) Range(f func(key K, val V) bool) {
+	for _, item := range m.store {
+		if !f(item.k, item.v) {
+			return
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *intSortedSliceMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (m *intSortedSliceMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[K, V](data, m)
+}
+
+func (m *intSortedSliceMap[K, V]) backendType() BackendType {
+	return BackendIntSortedSliceMap
+}
+
+func (m *intSortedSliceMap[K, V]) describe() Description {
+	c := sortedComplexity
+	c.Load = filteredTime
+	return Description{Backend: "IntSortedSliceMap", Capabilities: CapOrdered | CapFiltered, Complexity: c}
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (m *intSortedSliceMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeSnapshot[K, V](w, BackendIntSortedSliceMap, m, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// ReadFrom implements the io.ReaderFrom interface, replacing the content with a snapshot.
+// A snapshot of a sorted map is loaded in O(n).
+func (m *intSortedSliceMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	items, n, err := readSortedSnapshot[K, V](r)
+	if err != nil {
+		return n, err
+	}
+	m.store = make([]intSliceItem[K, V], len(items))
+	m.bloomFilter = bf.BloomFilter[K](0)
+	for i, item := range items {
+		m.store[i] = intSliceItem[K, V](item)
+		m.bloomFilter.Add(item.k)
+	}
+	return n, nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, see WriteTo
+func (m *intSortedSliceMap[K, V]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, see ReadFrom
+func (m *intSortedSliceMap[K, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m)
+}
+
+// GobEncode implements the gob.GobEncoder interface
+func (m *intSortedSliceMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface
+func (m *intSortedSliceMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
 }
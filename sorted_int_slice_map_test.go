@@ -131,3 +131,19 @@ func TestIntSortedSliceMap_Additional(t *testing.T) {
 		t.Errorf("Clear: Expected map to be empty, but it still contains keys")
 	}
 }
+
+func TestIntSortedSliceMap_BloomFilter(t *testing.T) {
+	m := NewIntSortedSliceMap[int, string]().(*intSortedSliceMap[int, string])
+
+	// The filter can't tell anything about the key 0
+	m.Store(0, "zero")
+	if val, ok := m.Load(0); !ok || val != "zero" {
+		t.Errorf("Load: Expected 'zero', but got '%s'", val)
+	}
+
+	m.Store(6, "six")
+	m.Clear()
+	if m.bloomFilter != 0 {
+		t.Errorf("Clear: Expected the bloom filter to be reset, but got %b", m.bloomFilter)
+	}
+}
@@ -1,6 +1,9 @@
 package gomap
 
 import (
+	"io"
+	"slices"
+
 	"golang.org/x/exp/constraints"
 )
 
@@ -10,7 +13,8 @@ type sliceItem[K constraints.Ordered, V any] struct {
 }
 
 type sortedSliceMap[K constraints.Ordered, V any] struct {
-	store []sliceItem[K, V]
+	store  []sliceItem[K, V]
+	shared bool // store is shared with a FrozenMap, so it is copied before the next write
 }
 
 // NewSortedSliceMap create sorted slice map,
@@ -57,6 +61,7 @@ func (m *sortedSliceMap[K, V]) binarySearch(key K) (int, bool) {
 }
 
 func (m *sortedSliceMap[K, V]) Store(key K, val V) {
+	m.own()
 	idx, exist := m.binarySearch(key)
 
 	if !exist {
@@ -89,6 +94,7 @@ func (m *sortedSliceMap[K, V]) LoadAndDelete(key K) (V, bool) {
 func (m *sortedSliceMap[K, V]) Delete(key K) {
 	idx, found := m.binarySearch(key)
 	if found {
+		m.own()
 		// Remove the key-value pair at the found index by slicing the store.
 		m.store = m.store[:idx+copy(m.store[idx:], m.store[idx+1:])]
 	}
@@ -101,4 +107,86 @@ func (m *sortedSliceMap[K, V]) Contain(key K) bool {
 
 func (m *sortedSliceMap[K, V]) Clear() {
 	m.store = make([]sliceItem[K, V], 0)
+	m.shared = false
+}
+
+func (m *sortedSliceMap[K, V]) Len() int {
+	return len(m.store)
+}
+
+func (m *sortedSliceMap[K, V]) Range(f func(key K, val V) bool) {
+	for _, item := range m.store {
+		if !f(item.k, item.v) {
+			return
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *sortedSliceMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (m *sortedSliceMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[K, V](data, m)
+}
+
+func (m *sortedSliceMap[K, V]) backendType() BackendType {
+	return BackendSortedSliceMap
+}
+
+func (m *sortedSliceMap[K, V]) describe() Description {
+	return Description{Backend: "SortedSliceMap", Capabilities: CapOrdered, Complexity: sortedComplexity}
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (m *sortedSliceMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeSnapshot[K, V](w, BackendSortedSliceMap, m, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// ReadFrom implements the io.ReaderFrom interface, replacing the content with a snapshot.
+// A snapshot of a sorted map is loaded in O(n).
+func (m *sortedSliceMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	items, n, err := readSortedSnapshot[K, V](r)
+	if err != nil {
+		return n, err
+	}
+	m.store = items
+	m.shared = false
+	return n, nil
+}
+
+// own copies the store if it is shared with a FrozenMap, before it is written
+func (m *sortedSliceMap[K, V]) own() {
+	if m.shared {
+		m.store = slices.Clone(m.store)
+		m.shared = false
+	}
+}
+
+// freeze shares the store with a FrozenMap
+func (m *sortedSliceMap[K, V]) freeze() FrozenMap[K, V] {
+	m.shared = true
+	return &frozenSortedMap[K, V]{store: m.store}
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, see WriteTo
+func (m *sortedSliceMap[K, V]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, see ReadFrom
+func (m *sortedSliceMap[K, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m)
+}
+
+// GobEncode implements the gob.GobEncoder interface
+func (m *sortedSliceMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface
+func (m *sortedSliceMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
 }
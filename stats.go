@@ -0,0 +1,27 @@
+package gomap
+
+// Stats are the counters of a map
+type Stats struct {
+	Hits      uint64 // Loads of an existing key
+	Misses    uint64 // Loads of a missing key
+	Evictions uint64 // Entries evicted to respect the capacity
+}
+
+// HitRate returns the ratio of Loads which found their key,
+// or 0 if there was no Load
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// add returns the sum of both stats
+func (s Stats) add(o Stats) Stats {
+	return Stats{
+		Hits:      s.Hits + o.Hits,
+		Misses:    s.Misses + o.Misses,
+		Evictions: s.Evictions + o.Evictions,
+	}
+}
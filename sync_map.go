@@ -1,6 +1,7 @@
 package gomap
 
 import (
+	"io"
 	"sync"
 )
 
@@ -8,6 +9,9 @@ type syncMap[K comparable, V any] struct {
 	store sync.Map
 }
 
+// NewSyncMap creates a map backed by sync.Map, whose reads take no lock.
+// Unlike the other thread-safe maps it has no Txn, Watch nor LoadWait, which would need a lock over the whole map.
+// thread-safe
 func NewSyncMap[K comparable, V any](opts ...Option) Map[K, V] {
 	opt := option{}
 	for _, o := range opts {
@@ -57,3 +61,68 @@ func (m *syncMap[K, V]) Clear() {
 		return true
 	})
 }
+
+// Len counts the entries, it is O(n)
+func (m *syncMap[K, V]) Len() int {
+	n := 0
+	m.store.Range(func(key, value any) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+func (m *syncMap[K, V]) Range(f func(key K, val V) bool) {
+	m.store.Range(func(key, value any) bool {
+		return f(key.(K), value.(V))
+	})
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *syncMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (m *syncMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[K, V](data, m)
+}
+
+func (m *syncMap[K, V]) backendType() BackendType {
+	return BackendSyncMap
+}
+
+func (m *syncMap[K, V]) describe() Description {
+	return Description{Backend: "SyncMap", Capabilities: CapThreadSafe, Complexity: hashComplexity}
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (m *syncMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeSnapshot[K, V](w, BackendSyncMap, m, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// ReadFrom implements the io.ReaderFrom interface, replacing the content with a snapshot
+func (m *syncMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	return ReadSnapshot[K, V](r, m, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, see WriteTo.
+// A sync.Map has no lock to hold, so the writes made meanwhile may be partly encoded.
+func (m *syncMap[K, V]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, see ReadFrom
+func (m *syncMap[K, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m)
+}
+
+// GobEncode implements the gob.GobEncoder interface
+func (m *syncMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface
+func (m *syncMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
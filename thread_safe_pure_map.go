@@ -1,17 +1,35 @@
 package gomap
 
-import "sync"
+import (
+	"context"
+	"io"
+	"maps"
+	"sync"
+)
 
 // Define the threadSafePureMap struct
 type threadSafePureMap[K comparable, V any] struct {
-	store map[K]V
-	mu    sync.RWMutex // Mutex for thread-safety
+	store  map[K]V
+	mu     sync.RWMutex // Mutex for thread-safety
+	shared bool         // store is shared with a FrozenMap, so it is copied before the next write
+
+	version uint64 // increased by every write, used by optimistic transactions
+	txnMode TxnMode
+
+	watches watchHub[K, V]
+	waits   waitHub[K]
 }
 
 // NewThreadSafePureMap creates a new threadSafePureMap instance
-func NewThreadSafePureMap[K comparable, V any]() Map[K, V] {
+func NewThreadSafePureMap[K comparable, V any](opts ...Option) Map[K, V] {
+	opt := option{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
 	return &threadSafePureMap[K, V]{
-		store: make(map[K]V),
+		store:   make(map[K]V),
+		txnMode: opt.txnMode,
 	}
 }
 
@@ -19,15 +37,14 @@ func NewThreadSafePureMap[K comparable, V any]() Map[K, V] {
 func (pm *threadSafePureMap[K, V]) Store(key K, val V) {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
-	pm.store[key] = val
+	pm.storeLocked(key, val)
 }
 
 // Load implements the Load method of the Map interface
 func (pm *threadSafePureMap[K, V]) Load(key K) (V, bool) {
 	pm.mu.RLock()
 	defer pm.mu.RUnlock()
-	val, ok := pm.store[key]
-	return val, ok
+	return pm.loadLocked(key)
 }
 
 // LoadAndDelete implements the LoadAndDelete method of the Map interface
@@ -36,7 +53,7 @@ func (pm *threadSafePureMap[K, V]) LoadAndDelete(key K) (V, bool) {
 	defer pm.mu.Unlock()
 	val, ok := pm.store[key]
 	if ok {
-		delete(pm.store, key)
+		pm.deleteLocked(key)
 	}
 	return val, ok
 }
@@ -45,7 +62,7 @@ func (pm *threadSafePureMap[K, V]) LoadAndDelete(key K) (V, bool) {
 func (pm *threadSafePureMap[K, V]) Delete(key K) {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
-	delete(pm.store, key)
+	pm.deleteLocked(key)
 }
 
 // Contain implements the Contain method of the Map interface
@@ -60,5 +77,155 @@ func (pm *threadSafePureMap[K, V]) Contain(key K) bool {
 func (pm *threadSafePureMap[K, V]) Clear() {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
+	if pm.watches.active() {
+		for key, val := range pm.store {
+			pm.watches.notify(Event[K, V]{Type: EventClear, Key: key, OldValue: val, HadOld: true})
+		}
+	}
 	pm.store = make(map[K]V)
+	pm.shared = false
+	pm.version++
+}
+
+// Txn implements the Txn method of the Transactional interface
+func (pm *threadSafePureMap[K, V]) Txn(fn func(tx Tx[K, V]) error) error {
+	return runTxn[K, V](&pm.mu, &pm.version, pm.txnMode, pm, fn)
+}
+
+// Watch implements the Watch method of the Watchable interface
+func (pm *threadSafePureMap[K, V]) Watch(ctx context.Context, key K, opts ...Option) <-chan Event[K, V] {
+	return pm.watches.watch(ctx, keyMatcher(key), opts)
+}
+
+func (pm *threadSafePureMap[K, V]) loadLocked(key K) (V, bool) {
+	val, ok := pm.store[key]
+	return val, ok
+}
+
+func (pm *threadSafePureMap[K, V]) storeLocked(key K, val V) {
+	old, had := pm.store[key]
+	pm.ownLocked()
+	pm.store[key] = val
+	pm.version++
+	pm.watches.notify(Event[K, V]{Type: EventPut, Key: key, OldValue: old, NewValue: val, HadOld: had})
+	pm.waits.wake(key)
+}
+
+func (pm *threadSafePureMap[K, V]) deleteLocked(key K) {
+	old, had := pm.store[key]
+	if !had {
+		return
+	}
+	pm.ownLocked()
+	delete(pm.store, key)
+	pm.version++
+	pm.watches.notify(Event[K, V]{Type: EventDelete, Key: key, OldValue: old, HadOld: true})
+}
+
+// LoadWait implements the LoadWait method of the Waitable interface
+func (pm *threadSafePureMap[K, V]) LoadWait(ctx context.Context, key K) (V, error) {
+	return loadWait(ctx, &pm.mu, &pm.waits, pm.loadLocked, key)
+}
+
+// Len implements the Len method of the Iterable interface
+func (pm *threadSafePureMap[K, V]) Len() int {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return len(pm.store)
+}
+
+// Range implements the Range method of the Iterable interface
+func (pm *threadSafePureMap[K, V]) Range(f func(key K, val V) bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	for key, val := range pm.store {
+		if !f(key, val) {
+			return
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (pm *threadSafePureMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](pm)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (pm *threadSafePureMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[K, V](data, pm)
+}
+
+func (pm *threadSafePureMap[K, V]) backendType() BackendType {
+	return BackendThreadSafePureMap
+}
+
+func (pm *threadSafePureMap[K, V]) describe() Description {
+	return Description{Backend: "ThreadSafePureMap", Capabilities: CapThreadSafe, Complexity: hashComplexity}
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (pm *threadSafePureMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeSnapshot[K, V](w, BackendThreadSafePureMap, pm, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// ReadFrom implements the io.ReaderFrom interface, replacing the content with a snapshot.
+// It notifies the watchers like a Clear followed by a Store of every loaded entry.
+func (pm *threadSafePureMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	store, n, err := readMapSnapshot[K, V](r)
+	if err != nil {
+		return n, err
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.watches.active() {
+		for key, val := range pm.store {
+			pm.watches.notify(Event[K, V]{Type: EventClear, Key: key, OldValue: val, HadOld: true})
+		}
+	}
+	pm.store = store
+	pm.shared = false
+	pm.version++
+	for key, val := range pm.store {
+		pm.watches.notify(Event[K, V]{Type: EventPut, Key: key, NewValue: val})
+		pm.waits.wake(key)
+	}
+	return n, nil
+}
+
+// ownLocked copies the store if it is shared with a FrozenMap, before it is written
+func (pm *threadSafePureMap[K, V]) ownLocked() {
+	if pm.shared {
+		pm.store = maps.Clone(pm.store)
+		pm.shared = false
+	}
+}
+
+// freeze shares the store with a FrozenMap
+func (pm *threadSafePureMap[K, V]) freeze() FrozenMap[K, V] {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.shared = true
+	return &frozenHashMap[K, V]{store: pm.store, threadSafe: true}
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, see WriteTo
+func (pm *threadSafePureMap[K, V]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(pm)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, see ReadFrom
+func (pm *threadSafePureMap[K, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, pm)
+}
+
+// GobEncode implements the gob.GobEncoder interface
+func (pm *threadSafePureMap[K, V]) GobEncode() ([]byte, error) {
+	return pm.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface
+func (pm *threadSafePureMap[K, V]) GobDecode(data []byte) error {
+	return pm.UnmarshalBinary(data)
 }
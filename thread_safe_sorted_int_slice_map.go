@@ -1,6 +1,8 @@
 package gomap
 
 import (
+	"context"
+	"io"
 	"sync"
 
 	bf "github.com/lovung/bloomfilter"
@@ -12,6 +14,12 @@ type threadSafeIntSortedSliceMap[K constraints.Integer, V any] struct {
 	store       []intSliceItem[K, V]
 	bloomFilter bf.BloomFilter[K]
 	mu          sync.RWMutex // Mutex for thread-safety
+
+	version uint64 // increased by every write, used by optimistic transactions
+	txnMode TxnMode
+
+	watches watchHub[K, V]
+	waits   waitHub[K]
 }
 
 // NewThreadSafeIntSortedSliceMap creates a new threadSafeIntSortedSliceMap instance
@@ -24,6 +32,7 @@ func NewThreadSafeIntSortedSliceMap[K constraints.Integer, V any](opts ...Option
 	m := &threadSafeIntSortedSliceMap[K, V]{
 		store:       make([]intSliceItem[K, V], 0),
 		bloomFilter: bf.BloomFilter[K](0),
+		txnMode:     opt.txnMode,
 	}
 	return m
 }
@@ -53,9 +62,16 @@ func (m *threadSafeIntSortedSliceMap[K, V]) Store(key K, val V) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.storeLocked(key, val)
+}
+
+func (m *threadSafeIntSortedSliceMap[K, V]) storeLocked(key K, val V) {
 	idx, exist := m.binarySearch(key)
 
-	if !exist {
+	var old V
+	if exist {
+		old = m.store[idx].v
+	} else {
 		// Key doesn't exist, insert it at the correct position.
 		m.store = append(m.store, intSliceItem[K, V]{key, val})
 		copy(m.store[idx+1:], m.store[idx:len(m.store)-1])
@@ -63,14 +79,21 @@ func (m *threadSafeIntSortedSliceMap[K, V]) Store(key K, val V) {
 	m.store[idx].k = key
 	m.store[idx].v = val
 	m.bloomFilter.Add(key)
+	m.version++
+	m.watches.notify(Event[K, V]{Type: EventPut, Key: key, OldValue: old, NewValue: val, HadOld: exist})
+	m.waits.wake(key)
 }
 
 func (m *threadSafeIntSortedSliceMap[K, V]) Load(key K) (V, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	return m.loadLocked(key)
+}
+
+func (m *threadSafeIntSortedSliceMap[K, V]) loadLocked(key K) (V, bool) {
 	var zero V
-	if !m.bloomFilter.MayExist(key) {
+	if !mayExist(m.bloomFilter, key) {
 		return zero, false
 	}
 	idx, exist := m.binarySearch(key)
@@ -81,13 +104,13 @@ func (m *threadSafeIntSortedSliceMap[K, V]) Load(key K) (V, bool) {
 }
 
 func (m *threadSafeIntSortedSliceMap[K, V]) LoadAndDelete(key K) (V, bool) {
-	var zero V
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	v, exist := m.Load(key)
-	if !exist {
-		return zero, exist
+	v, exist := m.loadLocked(key)
+	if exist {
+		m.deleteLocked(key)
 	}
-	m.Delete(key)
 	return v, exist
 }
 
@@ -95,10 +118,17 @@ func (m *threadSafeIntSortedSliceMap[K, V]) Delete(key K) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.deleteLocked(key)
+}
+
+func (m *threadSafeIntSortedSliceMap[K, V]) deleteLocked(key K) {
 	idx, found := m.binarySearch(key)
 	if found {
+		old := m.store[idx].v
 		// Remove the key-value pair at the found index by slicing the store.
 		m.store = m.store[:idx+copy(m.store[idx:], m.store[idx+1:])]
+		m.version++
+		m.watches.notify(Event[K, V]{Type: EventDelete, Key: key, OldValue: old, HadOld: true})
 	}
 }
 
@@ -106,7 +136,7 @@ func (m *threadSafeIntSortedSliceMap[K, V]) Contain(key K) bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if !m.bloomFilter.MayExist(key) {
+	if !mayExist(m.bloomFilter, key) {
 		return false
 	}
 	_, exist := m.binarySearch(key)
@@ -117,5 +147,123 @@ func (m *threadSafeIntSortedSliceMap[K, V]) Clear() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.watches.active() {
+		for _, item := range m.store {
+			m.watches.notify(Event[K, V]{Type: EventClear, Key: item.k, OldValue: item.v, HadOld: true})
+		}
+	}
 	m.store = make([]intSliceItem[K, V], 0)
+	m.bloomFilter = bf.BloomFilter[K](0)
+	m.version++
+}
+
+// Txn implements the Txn method of the Transactional interface
+func (m *threadSafeIntSortedSliceMap[K, V]) Txn(fn func(tx Tx[K, V]) error) error {
+	return runTxn[K, V](&m.mu, &m.version, m.txnMode, m, fn)
+}
+
+// Watch implements the Watch method of the Watchable interface
+func (m *threadSafeIntSortedSliceMap[K, V]) Watch(ctx context.Context, key K, opts ...Option) <-chan Event[K, V] {
+	return m.watches.watch(ctx, keyMatcher(key), opts)
+}
+
+// WatchRange implements the WatchRange method of the RangeWatchable interface
+func (m *threadSafeIntSortedSliceMap[K, V]) WatchRange(ctx context.Context, lo, hi K, opts ...Option) <-chan Event[K, V] {
+	return m.watches.watch(ctx, rangeMatcher(lo, hi), opts)
+}
+
+// LoadWait implements the LoadWait method of the Waitable interface
+func (m *threadSafeIntSortedSliceMap[K, V]) LoadWait(ctx context.Context, key K) (V, error) {
+	return loadWait(ctx, &m.mu, &m.waits, m.loadLocked, key)
+}
+
+func (m *threadSafeIntSortedSliceMap[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.store)
+}
+
+func (m *threadSafeIntSortedSliceMap[K, V]) Range(f func(key K, val V) bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, item := range m.store {
+		if !f(item.k, item.v) {
+			return
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *threadSafeIntSortedSliceMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (m *threadSafeIntSortedSliceMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[K, V](data, m)
+}
+
+func (m *threadSafeIntSortedSliceMap[K, V]) backendType() BackendType {
+	return BackendThreadSafeIntSortedSliceMap
+}
+
+func (m *threadSafeIntSortedSliceMap[K, V]) describe() Description {
+	c := sortedComplexity
+	c.Load = filteredTime
+	return Description{Backend: "ThreadSafeIntSortedSliceMap", Capabilities: CapOrdered | CapThreadSafe | CapFiltered, Complexity: c}
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (m *threadSafeIntSortedSliceMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeSnapshot[K, V](w, BackendThreadSafeIntSortedSliceMap, m, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// ReadFrom implements the io.ReaderFrom interface, replacing the content with a snapshot.
+// A snapshot of a sorted map is loaded in O(n).
+// It notifies the watchers like a Clear followed by a Store of every loaded entry.
+func (m *threadSafeIntSortedSliceMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	items, n, err := readSortedSnapshot[K, V](r)
+	if err != nil {
+		return n, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.watches.active() {
+		for _, item := range m.store {
+			m.watches.notify(Event[K, V]{Type: EventClear, Key: item.k, OldValue: item.v, HadOld: true})
+		}
+	}
+	m.store = make([]intSliceItem[K, V], len(items))
+	m.bloomFilter = bf.BloomFilter[K](0)
+	for i, item := range items {
+		m.store[i] = intSliceItem[K, V](item)
+		m.bloomFilter.Add(item.k)
+		m.watches.notify(Event[K, V]{Type: EventPut, Key: item.k, NewValue: item.v})
+		m.waits.wake(item.k)
+	}
+	m.version++
+	return n, nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, see WriteTo
+func (m *threadSafeIntSortedSliceMap[K, V]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, see ReadFrom
+func (m *threadSafeIntSortedSliceMap[K, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m)
+}
+
+// GobEncode implements the gob.GobEncoder interface
+func (m *threadSafeIntSortedSliceMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface
+func (m *threadSafeIntSortedSliceMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
 }
@@ -108,3 +108,19 @@ func TestThreadSafeIntSortedSliceMap_Empty(t *testing.T) {
 	// Test Delete method for non-existent key
 	m.Delete(3) // Deleting a non-existent key should not cause an error
 }
+
+func TestThreadSafeIntSortedSliceMap_BloomFilter(t *testing.T) {
+	m := NewThreadSafeIntSortedSliceMap[int, string]().(*threadSafeIntSortedSliceMap[int, string])
+
+	// The filter can't tell anything about the key 0
+	m.Store(0, "zero")
+	if !m.Contain(0) {
+		t.Errorf("Contain: Expected key 0 to exist, but it doesn't")
+	}
+
+	m.Store(6, "six")
+	m.Clear()
+	if m.bloomFilter != 0 {
+		t.Errorf("Clear: Expected the bloom filter to be reset, but got %b", m.bloomFilter)
+	}
+}
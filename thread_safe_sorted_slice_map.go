@@ -1,6 +1,9 @@
 package gomap
 
 import (
+	"context"
+	"io"
+	"slices"
 	"sync"
 
 	"golang.org/x/exp/constraints"
@@ -8,8 +11,15 @@ import (
 
 // Define the threadSafeSortedSliceMap struct
 type threadSafeSortedSliceMap[K constraints.Ordered, V any] struct {
-	store []sliceItem[K, V]
-	mu    sync.RWMutex // Mutex for thread-safety
+	store  []sliceItem[K, V]
+	mu     sync.RWMutex // Mutex for thread-safety
+	shared bool         // store is shared with a FrozenMap, so it is copied before the next write
+
+	version uint64 // increased by every write, used by optimistic transactions
+	txnMode TxnMode
+
+	watches watchHub[K, V]
+	waits   waitHub[K]
 }
 
 // NewThreadSafeSortedSliceMap creates a new threadSafeSortedSliceMap instance
@@ -21,7 +31,8 @@ func NewThreadSafeSortedSliceMap[K constraints.Ordered, V any](opts ...Option) M
 	}
 
 	m := &threadSafeSortedSliceMap[K, V]{
-		store: make([]sliceItem[K, V], 0),
+		store:   make([]sliceItem[K, V], 0),
+		txnMode: opt.txnMode,
 	}
 	return m
 }
@@ -51,22 +62,37 @@ func (m *threadSafeSortedSliceMap[K, V]) Store(key K, val V) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.storeLocked(key, val)
+}
+
+func (m *threadSafeSortedSliceMap[K, V]) storeLocked(key K, val V) {
+	m.ownLocked()
 	idx, exist := m.binarySearch(key)
 
-	if !exist {
+	var old V
+	if exist {
+		old = m.store[idx].v
+	} else {
 		// Key doesn't exist, insert it at the correct position.
 		m.store = append(m.store, sliceItem[K, V]{key, val})
 		copy(m.store[idx+1:], m.store[idx:len(m.store)-1])
 	}
 	m.store[idx].k = key
 	m.store[idx].v = val
+	m.version++
+	m.watches.notify(Event[K, V]{Type: EventPut, Key: key, OldValue: old, NewValue: val, HadOld: exist})
+	m.waits.wake(key)
 }
 
 func (m *threadSafeSortedSliceMap[K, V]) Load(key K) (V, bool) {
-	var zero V
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	return m.loadLocked(key)
+}
+
+func (m *threadSafeSortedSliceMap[K, V]) loadLocked(key K) (V, bool) {
+	var zero V
 	idx, exist := m.binarySearch(key)
 	if !exist {
 		return zero, exist
@@ -75,11 +101,13 @@ func (m *threadSafeSortedSliceMap[K, V]) Load(key K) (V, bool) {
 }
 
 func (m *threadSafeSortedSliceMap[K, V]) LoadAndDelete(key K) (V, bool) {
-	v, exist := m.Load(key)
-	if !exist {
-		return v, exist
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, exist := m.loadLocked(key)
+	if exist {
+		m.deleteLocked(key)
 	}
-	m.Delete(key)
 	return v, exist
 }
 
@@ -87,10 +115,18 @@ func (m *threadSafeSortedSliceMap[K, V]) Delete(key K) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.deleteLocked(key)
+}
+
+func (m *threadSafeSortedSliceMap[K, V]) deleteLocked(key K) {
 	idx, found := m.binarySearch(key)
 	if found {
+		m.ownLocked()
+		old := m.store[idx].v
 		// Remove the key-value pair at the found index by slicing the store.
 		m.store = m.store[:idx+copy(m.store[idx:], m.store[idx+1:])]
+		m.version++
+		m.watches.notify(Event[K, V]{Type: EventDelete, Key: key, OldValue: old, HadOld: true})
 	}
 }
 
@@ -106,5 +142,136 @@ func (m *threadSafeSortedSliceMap[K, V]) Clear() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.watches.active() {
+		for _, item := range m.store {
+			m.watches.notify(Event[K, V]{Type: EventClear, Key: item.k, OldValue: item.v, HadOld: true})
+		}
+	}
 	m.store = make([]sliceItem[K, V], 0)
+	m.shared = false
+	m.version++
+}
+
+// Txn implements the Txn method of the Transactional interface
+func (m *threadSafeSortedSliceMap[K, V]) Txn(fn func(tx Tx[K, V]) error) error {
+	return runTxn[K, V](&m.mu, &m.version, m.txnMode, m, fn)
+}
+
+// Watch implements the Watch method of the Watchable interface
+func (m *threadSafeSortedSliceMap[K, V]) Watch(ctx context.Context, key K, opts ...Option) <-chan Event[K, V] {
+	return m.watches.watch(ctx, keyMatcher(key), opts)
+}
+
+// WatchRange implements the WatchRange method of the RangeWatchable interface
+func (m *threadSafeSortedSliceMap[K, V]) WatchRange(ctx context.Context, lo, hi K, opts ...Option) <-chan Event[K, V] {
+	return m.watches.watch(ctx, rangeMatcher(lo, hi), opts)
+}
+
+// LoadWait implements the LoadWait method of the Waitable interface
+func (m *threadSafeSortedSliceMap[K, V]) LoadWait(ctx context.Context, key K) (V, error) {
+	return loadWait(ctx, &m.mu, &m.waits, m.loadLocked, key)
+}
+
+func (m *threadSafeSortedSliceMap[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.store)
+}
+
+func (m *threadSafeSortedSliceMap[K, V]) Range(f func(key K, val V) bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, item := range m.store {
+		if !f(item.k, item.v) {
+			return
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *threadSafeSortedSliceMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[K, V](m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (m *threadSafeSortedSliceMap[K, V]) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[K, V](data, m)
+}
+
+func (m *threadSafeSortedSliceMap[K, V]) backendType() BackendType {
+	return BackendThreadSafeSortedSliceMap
+}
+
+func (m *threadSafeSortedSliceMap[K, V]) describe() Description {
+	return Description{Backend: "ThreadSafeSortedSliceMap", Capabilities: CapOrdered | CapThreadSafe, Complexity: sortedComplexity}
+}
+
+// WriteTo implements the io.WriterTo interface, writing a snapshot with the default codecs
+func (m *threadSafeSortedSliceMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	return writeSnapshot[K, V](w, BackendThreadSafeSortedSliceMap, m, DefaultCodec[K](), DefaultCodec[V]())
+}
+
+// ReadFrom implements the io.ReaderFrom interface, replacing the content with a snapshot.
+// A snapshot of a sorted map is loaded in O(n).
+// It notifies the watchers like a Clear followed by a Store of every loaded entry.
+func (m *threadSafeSortedSliceMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	items, n, err := readSortedSnapshot[K, V](r)
+	if err != nil {
+		return n, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.watches.active() {
+		for _, item := range m.store {
+			m.watches.notify(Event[K, V]{Type: EventClear, Key: item.k, OldValue: item.v, HadOld: true})
+		}
+	}
+	m.store = items
+	m.shared = false
+	m.version++
+	for _, item := range m.store {
+		m.watches.notify(Event[K, V]{Type: EventPut, Key: item.k, NewValue: item.v})
+		m.waits.wake(item.k)
+	}
+	return n, nil
+}
+
+// ownLocked copies the store if it is shared with a FrozenMap, before it is written
+func (m *threadSafeSortedSliceMap[K, V]) ownLocked() {
+	if m.shared {
+		m.store = slices.Clone(m.store)
+		m.shared = false
+	}
+}
+
+// freeze shares the store with a FrozenMap
+func (m *threadSafeSortedSliceMap[K, V]) freeze() FrozenMap[K, V] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shared = true
+	return &frozenSortedMap[K, V]{store: m.store, threadSafe: true}
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, see WriteTo
+func (m *threadSafeSortedSliceMap[K, V]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(m)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface, see ReadFrom
+func (m *threadSafeSortedSliceMap[K, V]) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, m)
+}
+
+// GobEncode implements the gob.GobEncoder interface
+func (m *threadSafeSortedSliceMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface
+func (m *threadSafeSortedSliceMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
 }
@@ -0,0 +1,174 @@
+package gomap
+
+const (
+	sketchDepth   = 4
+	sketchMaxFreq = 15
+)
+
+// countMinSketch estimates the frequency of the keys in constant memory.
+// The counters are halved regularly so old popularity fades away.
+type countMinSketch[K comparable] struct {
+	rows      [sketchDepth][]uint8
+	mask      uint64
+	additions int
+	resetAt   int
+}
+
+func newCountMinSketch[K comparable](capacity int) *countMinSketch[K] {
+	width := 16
+	for width < capacity {
+		width <<= 1
+	}
+	s := &countMinSketch[K]{
+		mask:    uint64(width - 1),
+		resetAt: 10 * width,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+func (s *countMinSketch[K]) index(key K, row int) uint64 {
+	return hashKey(key, uint64(row)+1) & s.mask
+}
+
+func (s *countMinSketch[K]) Increment(key K) {
+	for i := range s.rows {
+		if idx := s.index(key, i); s.rows[i][idx] < sketchMaxFreq {
+			s.rows[i][idx]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.age()
+	}
+}
+
+func (s *countMinSketch[K]) Estimate(key K) uint8 {
+	freq := uint8(sketchMaxFreq)
+	for i := range s.rows {
+		freq = min(freq, s.rows[i][s.index(key, i)])
+	}
+	return freq
+}
+
+func (s *countMinSketch[K]) age() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
+
+func (s *countMinSketch[K]) Reset() {
+	for i := range s.rows {
+		clear(s.rows[i])
+	}
+	s.additions = 0
+}
+
+type tinyLFUPolicy[K comparable] struct {
+	windowCap    int
+	mainCap      int
+	protectedCap int
+
+	window    *keyList[K] // LRU admission window
+	probation *keyList[K] // main segment of the keys on trial
+	protected *keyList[K] // main segment of the keys used while on trial
+	sketch    *countMinSketch[K]
+}
+
+// NewWTinyLFUPolicy evicts with the W-TinyLFU algorithm.
+// New keys go through a small LRU window, then must be estimated more frequent
+// than the victim of the main segmented LRU by a count-min sketch to be admitted.
+// capacity must be the capacity of the map.
+func NewWTinyLFUPolicy[K comparable](capacity int) EvictionPolicy[K] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	windowCap := max(capacity/100, 1)
+	mainCap := max(capacity-windowCap, 1)
+	return &tinyLFUPolicy[K]{
+		windowCap:    windowCap,
+		mainCap:      mainCap,
+		protectedCap: max(mainCap*8/10, 1),
+		window:       newKeyList[K](),
+		probation:    newKeyList[K](),
+		protected:    newKeyList[K](),
+		sketch:       newCountMinSketch[K](capacity),
+	}
+}
+
+func (p *tinyLFUPolicy[K]) Access(key K) {
+	p.sketch.Increment(key)
+
+	switch {
+	case p.window.Contain(key):
+		p.window.MoveToFront(key)
+	case p.protected.Contain(key):
+		p.protected.MoveToFront(key)
+	case p.probation.Remove(key):
+		p.protected.PushFront(key)
+		if p.protected.Len() > p.protectedCap {
+			demoted, _ := p.protected.PopBack()
+			p.probation.PushFront(demoted)
+		}
+	}
+}
+
+func (p *tinyLFUPolicy[K]) Insert(key K) {
+	p.sketch.Increment(key)
+	p.window.PushFront(key)
+}
+
+func (p *tinyLFUPolicy[K]) Remove(key K) {
+	if !p.window.Remove(key) && !p.probation.Remove(key) {
+		p.protected.Remove(key)
+	}
+}
+
+func (p *tinyLFUPolicy[K]) mainLen() int {
+	return p.probation.Len() + p.protected.Len()
+}
+
+// mainVictim returns the key the main segment would evict
+func (p *tinyLFUPolicy[K]) mainVictim() (*keyList[K], K, bool) {
+	if key, ok := p.probation.Back(); ok {
+		return p.probation, key, true
+	}
+	key, ok := p.protected.Back()
+	return p.protected, key, ok
+}
+
+func (p *tinyLFUPolicy[K]) Victim() (K, bool) {
+	for p.window.Len() > p.windowCap {
+		candidate, _ := p.window.PopBack()
+		if p.mainLen() < p.mainCap {
+			p.probation.PushFront(candidate)
+			continue
+		}
+
+		segment, victim, _ := p.mainVictim()
+		if p.sketch.Estimate(candidate) > p.sketch.Estimate(victim) {
+			segment.Remove(victim)
+			p.probation.PushFront(candidate)
+			return victim, true
+		}
+		return candidate, true
+	}
+
+	if _, victim, ok := p.mainVictim(); ok {
+		p.Remove(victim)
+		return victim, true
+	}
+	return p.window.PopBack()
+}
+
+func (p *tinyLFUPolicy[K]) Reset() {
+	p.window.Reset()
+	p.probation.Reset()
+	p.protected.Reset()
+	p.sketch.Reset()
+}
@@ -0,0 +1,263 @@
+package gomap
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// TTLMap is a Map whose entries expire.
+// An expired entry is a miss for Load and Contain,
+// and is removed from the backend by DeleteExpired or the janitor.
+type TTLMap[K comparable, V any] interface {
+	Map[K, V]
+	// StoreWithTTL stores an entry expiring after ttl, or never if ttl is 0
+	StoreWithTTL(key K, val V, ttl time.Duration)
+	// DeleteExpired removes the expired entries and returns how many they were
+	DeleteExpired() int
+	// Close stops the janitor
+	Close()
+}
+
+// WithDefaultTTL sets the TTL of the entries stored by Store, 0 means no expiration
+func WithDefaultTTL(d time.Duration) Option {
+	return func(o *option) {
+		o.defaultTTL = d
+	}
+}
+
+// WithJanitorInterval starts a goroutine calling DeleteExpired every d,
+// until Close is called
+func WithJanitorInterval(d time.Duration) Option {
+	return func(o *option) {
+		o.janitorInterval = d
+	}
+}
+
+type ttlItem[K comparable] struct {
+	key     K
+	expires time.Time
+	index   int // index in the heap
+}
+
+// ttlHeap is a min-heap of the expiration times
+type ttlHeap[K comparable] []*ttlItem[K]
+
+func (h ttlHeap[K]) Len() int           { return len(h) }
+func (h ttlHeap[K]) Less(i, j int) bool { return h[i].expires.Before(h[j].expires) }
+func (h ttlHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *ttlHeap[K]) Push(x any) {
+	item := x.(*ttlItem[K])
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *ttlHeap[K]) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+type ttlMap[K comparable, V any] struct {
+	backend    Map[K, V]
+	clock      Clock
+	defaultTTL time.Duration
+
+	mu sync.RWMutex // guards the backend together with the expirations
+	// sharedReads tells if the backend is thread-safe, so its reads can share mu,
+	// the reads of another backend may write to it, such as an LRUMap reordering its entries
+	sharedReads bool
+	heap        ttlHeap[K]
+	items       map[K]*ttlItem[K] // only the entries which expire
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTTLMap wraps backend to expire its entries.
+// The writes must go through the returned map, not backend.
+// The reads of a backend which is not thread-safe are serialized.
+// The OnEvict of a BoundedMap backend is set to drop the expirations of the entries it evicts,
+// which replaces the function set before.
+// thread-safe
+func NewTTLMap[K comparable, V any](backend Map[K, V], opts ...Option) TTLMap[K, V] {
+	opt := option{clock: systemClock{}}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	m := &ttlMap[K, V]{
+		backend:     backend,
+		clock:       opt.clock,
+		defaultTTL:  opt.defaultTTL,
+		sharedReads: Describe(backend).Capabilities.Has(CapThreadSafe),
+		items:       make(map[K]*ttlItem[K]),
+		stop:        make(chan struct{}),
+	}
+	if b, ok := backend.(BoundedMap[K, V]); ok {
+		// The backend evicts within the writes, which hold mu
+		b.OnEvict(func(key K, _ V, reason EvictReason) {
+			if reason == EvictCapacity {
+				m.forgetLocked(key)
+			}
+		})
+	}
+	if opt.janitorInterval > 0 {
+		go m.janitor(opt.janitorInterval)
+	}
+	return m
+}
+
+func (m *ttlMap[K, V]) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.DeleteExpired()
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+func (m *ttlMap[K, V]) Store(key K, val V) {
+	m.StoreWithTTL(key, val, m.defaultTTL)
+}
+
+func (m *ttlMap[K, V]) StoreWithTTL(key K, val V, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.backend.Store(key, val)
+
+	item, ok := m.items[key]
+	switch {
+	case ttl <= 0 && ok:
+		heap.Remove(&m.heap, item.index)
+		delete(m.items, key)
+	case ttl > 0 && ok:
+		item.expires = m.clock.Now().Add(ttl)
+		heap.Fix(&m.heap, item.index)
+	case ttl > 0:
+		item = &ttlItem[K]{key: key, expires: m.clock.Now().Add(ttl)}
+		heap.Push(&m.heap, item)
+		m.items[key] = item
+	}
+}
+
+// expiredLocked reports whether key has expired, the caller must hold mu
+func (m *ttlMap[K, V]) expiredLocked(key K) bool {
+	item, ok := m.items[key]
+	return ok && !m.clock.Now().Before(item.expires)
+}
+
+// rlock locks mu to read the backend
+func (m *ttlMap[K, V]) rlock() {
+	if m.sharedReads {
+		m.mu.RLock()
+	} else {
+		m.mu.Lock()
+	}
+}
+
+func (m *ttlMap[K, V]) runlock() {
+	if m.sharedReads {
+		m.mu.RUnlock()
+	} else {
+		m.mu.Unlock()
+	}
+}
+
+func (m *ttlMap[K, V]) Load(key K) (V, bool) {
+	m.rlock()
+	defer m.runlock()
+
+	if m.expiredLocked(key) {
+		var zero V
+		return zero, false
+	}
+	return m.backend.Load(key)
+}
+
+func (m *ttlMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expired := m.expiredLocked(key)
+	m.forgetLocked(key)
+	val, ok := m.backend.LoadAndDelete(key)
+	if expired {
+		var zero V
+		return zero, false
+	}
+	return val, ok
+}
+
+func (m *ttlMap[K, V]) Delete(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.forgetLocked(key)
+	m.backend.Delete(key)
+}
+
+func (m *ttlMap[K, V]) Contain(key K) bool {
+	m.rlock()
+	defer m.runlock()
+
+	return !m.expiredLocked(key) && m.backend.Contain(key)
+}
+
+func (m *ttlMap[K, V]) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.heap = nil
+	m.items = make(map[K]*ttlItem[K])
+	m.backend.Clear()
+}
+
+func (m *ttlMap[K, V]) DeleteExpired() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	n := 0
+	for len(m.heap) > 0 && !now.Before(m.heap[0].expires) {
+		item := heap.Pop(&m.heap).(*ttlItem[K])
+		delete(m.items, item.key)
+		m.backend.Delete(item.key)
+		n++
+	}
+	return n
+}
+
+func (m *ttlMap[K, V]) Close() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// forgetLocked drops the expiration of key, the caller must hold mu
+func (m *ttlMap[K, V]) forgetLocked(key K) {
+	if item, ok := m.items[key]; ok {
+		heap.Remove(&m.heap, item.index)
+		delete(m.items, key)
+	}
+}
+
+// describe reports the TTL map thread-safe whatever the backend,
+// as every access to the backend holds mu, exclusively if the backend is not thread-safe
+func (m *ttlMap[K, V]) describe() Description {
+	return wrapping("TTLMap", CapThreadSafe, CapFiltered|CapBounded|CapPersistent, Describe(m.backend))
+}
@@ -0,0 +1,131 @@
+package gomap
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTTLMap(t *testing.T) {
+	clock := newFakeClock()
+	backend := NewPureMap[int, string]()
+	m := NewTTLMap[int, string](backend, WithDefaultTTL(time.Minute), WithClock(clock))
+	defer m.Close()
+
+	m.Store(1, "one")
+	m.StoreWithTTL(2, "two", time.Hour)
+	m.StoreWithTTL(3, "three", 0)
+
+	val, ok := m.Load(1)
+	if !ok || val != "one" {
+		t.Errorf("Load: Expected value 'one', but got '%s'", val)
+	}
+
+	clock.Advance(time.Minute)
+	if _, ok := m.Load(1); ok {
+		t.Errorf("Load: Expected key 1 to be expired, but it isn't")
+	}
+	if m.Contain(1) {
+		t.Errorf("Contain: Expected key 1 to be expired, but it isn't")
+	}
+	if !m.Contain(2) || !m.Contain(3) {
+		t.Errorf("Contain: Expected keys 2 and 3 to exist, but they don't")
+	}
+
+	// Expired entries stay in the backend until reaped
+	if !backend.Contain(1) {
+		t.Errorf("DeleteExpired: Expected key 1 to be in the backend before reaping")
+	}
+	if n := m.DeleteExpired(); n != 1 {
+		t.Errorf("DeleteExpired: Expected 1 entry to be reaped, but got %d", n)
+	}
+	if backend.Contain(1) {
+		t.Errorf("DeleteExpired: Expected key 1 to be removed from the backend")
+	}
+
+	// Storing again resets the expiration
+	m.StoreWithTTL(2, "two", time.Minute)
+	clock.Advance(time.Hour)
+	if n := m.DeleteExpired(); n != 1 {
+		t.Errorf("DeleteExpired: Expected 1 entry to be reaped, but got %d", n)
+	}
+	if !m.Contain(3) {
+		t.Errorf("Contain: Expected key 3 never to expire, but it did")
+	}
+
+	// Test LoadAndDelete method of an expired key
+	m.Store(4, "four")
+	clock.Advance(time.Minute)
+	if _, ok := m.LoadAndDelete(4); ok {
+		t.Errorf("LoadAndDelete: Expected key 4 to be expired, but it isn't")
+	}
+
+	// Test Clear method
+	m.Store(5, "five")
+	m.Clear()
+	if m.Contain(3) || m.Contain(5) {
+		t.Errorf("Clear: Expected map to be empty, but it still contains keys")
+	}
+	if n := m.DeleteExpired(); n != 0 {
+		t.Errorf("Clear: Expected no expiration left, but got %d", n)
+	}
+}
+
+func TestTTLMap_Janitor(t *testing.T) {
+	backend := NewThreadSafePureMap[int, string]()
+	m := NewTTLMap[int, string](backend, WithJanitorInterval(time.Millisecond))
+	defer m.Close()
+
+	m.StoreWithTTL(1, "one", time.Millisecond)
+	deadline := time.Now().Add(time.Second)
+	for backend.Contain(1) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if backend.Contain(1) {
+		t.Errorf("Janitor: Expected key 1 to be reaped, but it wasn't")
+	}
+}
+
+func TestTTLMap_Evicted(t *testing.T) {
+	m := NewTTLMap[int, int](NewLRUMap[int, int](2), WithDefaultTTL(time.Hour))
+	defer m.Close()
+	for i := 0; i < 10; i++ {
+		m.Store(i, i)
+	}
+
+	// The expirations of the keys evicted by the backend are dropped
+	tm := m.(*ttlMap[int, int])
+	if len(tm.items) != 2 || len(tm.heap) != 2 {
+		t.Errorf("Store: Expected 2 expirations, but got %d items and %d in the heap", len(tm.items), len(tm.heap))
+	}
+	if _, ok := tm.items[9]; !ok {
+		t.Errorf("Store: Expected key 9 to expire, but it doesn't")
+	}
+}
+
+func TestTTLMap_BackendReads(t *testing.T) {
+	// The reads of an LRUMap reorder its entries, so they must not run concurrently
+	m := NewTTLMap[int, int](NewLRUMap[int, int](100), WithDefaultTTL(time.Hour))
+	defer m.Close()
+	for i := 0; i < 100; i++ {
+		m.Store(i, i)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				m.Load((i * (g + 1)) % 100)
+				m.Contain(i % 100)
+			}
+		}(g)
+	}
+	wg.Wait()
+	for i := 0; i < 100; i++ {
+		if val, ok := m.Load(i); !ok || val != i {
+			t.Fatalf("Load: Expected %d, but got %d", i, val)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package gomap
+
+import (
+	"errors"
+	"sync"
+)
+
+// TxnMode controls how Txn isolates a transaction from other writers
+type TxnMode int
+
+const (
+	// Pessimistic holds the write lock for the whole transaction
+	Pessimistic TxnMode = iota
+	// Optimistic runs the callback without holding the write lock
+	// and only commits if no other write happened in the meantime,
+	// otherwise the callback is run again
+	Optimistic
+)
+
+// maxTxnRetries is how many times an optimistic transaction is attempted
+// before giving up with ErrTxnConflict
+const maxTxnRetries = 16
+
+// ErrTxnConflict is returned when an optimistic transaction
+// keeps conflicting with other writers
+var ErrTxnConflict = errors.New("gomap: transaction conflict")
+
+// Tx is the view of a map inside a transaction.
+// Writes are buffered and only applied to the map when the transaction commits.
+type Tx[K comparable, V any] interface {
+	Load(key K) (V, bool)
+	Store(key K, val V)
+	Delete(key K)
+	Contain(key K) bool
+}
+
+// Transactional is implemented by the maps which support multi-key transactions.
+// Txn applies all writes made through tx atomically,
+// or discards them if fn returns an error, which is then returned by Txn.
+//
+// In Pessimistic mode fn must not call the methods of the map itself, only of tx.
+// In Optimistic mode fn can be called several times, so it should have no side effects.
+type Transactional[K comparable, V any] interface {
+	Map[K, V]
+	Txn(fn func(tx Tx[K, V]) error) error
+}
+
+// txnStore is the access to a backend without locking,
+// the caller must hold the lock of the backend
+type txnStore[K comparable, V any] interface {
+	loadLocked(key K) (V, bool)
+	storeLocked(key K, val V)
+	deleteLocked(key K)
+}
+
+type txnWrite[V any] struct {
+	val     V
+	deleted bool
+}
+
+type txn[K comparable, V any] struct {
+	base   txnStore[K, V]
+	mu     *sync.RWMutex // not nil if reads must take the read lock
+	writes map[K]txnWrite[V]
+	keys   []K // keys of writes in the order they were first written
+}
+
+func newTxn[K comparable, V any](base txnStore[K, V], mu *sync.RWMutex) *txn[K, V] {
+	return &txn[K, V]{
+		base:   base,
+		mu:     mu,
+		writes: make(map[K]txnWrite[V]),
+	}
+}
+
+func (tx *txn[K, V]) Load(key K) (V, bool) {
+	if w, ok := tx.writes[key]; ok {
+		var zero V
+		if w.deleted {
+			return zero, false
+		}
+		return w.val, true
+	}
+	if tx.mu != nil {
+		tx.mu.RLock()
+		defer tx.mu.RUnlock()
+	}
+	return tx.base.loadLocked(key)
+}
+
+func (tx *txn[K, V]) Store(key K, val V) {
+	tx.write(key, txnWrite[V]{val: val})
+}
+
+func (tx *txn[K, V]) Delete(key K) {
+	tx.write(key, txnWrite[V]{deleted: true})
+}
+
+func (tx *txn[K, V]) Contain(key K) bool {
+	_, ok := tx.Load(key)
+	return ok
+}
+
+func (tx *txn[K, V]) write(key K, w txnWrite[V]) {
+	if _, ok := tx.writes[key]; !ok {
+		tx.keys = append(tx.keys, key)
+	}
+	tx.writes[key] = w
+}
+
+// commit applies the buffered writes, the caller must hold the write lock
+func (tx *txn[K, V]) commit() {
+	for _, key := range tx.keys {
+		w := tx.writes[key]
+		if w.deleted {
+			tx.base.deleteLocked(key)
+		} else {
+			tx.base.storeLocked(key, w.val)
+		}
+	}
+}
+
+// runTxn runs fn as a transaction over base which is guarded by mu.
+// version must be increased by every write to base.
+func runTxn[K comparable, V any](
+	mu *sync.RWMutex, version *uint64, mode TxnMode,
+	base txnStore[K, V], fn func(tx Tx[K, V]) error,
+) error {
+	if mode == Optimistic {
+		return runOptimisticTxn(mu, version, base, fn)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	tx := newTxn(base, nil)
+	if err := fn(tx); err != nil {
+		return err
+	}
+	tx.commit()
+	return nil
+}
+
+func runOptimisticTxn[K comparable, V any](
+	mu *sync.RWMutex, version *uint64,
+	base txnStore[K, V], fn func(tx Tx[K, V]) error,
+) error {
+	for i := 0; i < maxTxnRetries; i++ {
+		mu.RLock()
+		start := *version
+		mu.RUnlock()
+
+		tx := newTxn(base, mu)
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		mu.Lock()
+		if *version == start {
+			tx.commit()
+			mu.Unlock()
+			return nil
+		}
+		mu.Unlock()
+	}
+	return ErrTxnConflict
+}
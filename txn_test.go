@@ -0,0 +1,110 @@
+package gomap
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func transfer(m Transactional[string, int], from, to string, amount int) error {
+	return m.Txn(func(tx Tx[string, int]) error {
+		balance, _ := tx.Load(from)
+		if balance < amount {
+			return errors.New("insufficient balance")
+		}
+		tx.Store(from, balance-amount)
+		other, _ := tx.Load(to)
+		tx.Store(to, other+amount)
+		return nil
+	})
+}
+
+func TestTxn(t *testing.T) {
+	factories := map[string]func(opts ...Option) Map[string, int]{
+		"ThreadSafePureMap":        NewThreadSafePureMap[string, int],
+		"ThreadSafeSortedSliceMap": NewThreadSafeSortedSliceMap[string, int],
+	}
+	for name, factory := range factories {
+		for _, mode := range []TxnMode{Pessimistic, Optimistic} {
+			m := factory(WithTxnMode(mode)).(Transactional[string, int])
+			m.Store("a", 100)
+
+			// Test commit
+			if err := transfer(m, "a", "b", 30); err != nil {
+				t.Errorf("%s/%d: Expected transfer to succeed, but got %v", name, mode, err)
+			}
+			if val, _ := m.Load("a"); val != 70 {
+				t.Errorf("%s/%d: Expected balance 70, but got %d", name, mode, val)
+			}
+			if val, _ := m.Load("b"); val != 30 {
+				t.Errorf("%s/%d: Expected balance 30, but got %d", name, mode, val)
+			}
+
+			// Test rollback
+			if err := transfer(m, "a", "b", 1000); err == nil {
+				t.Errorf("%s/%d: Expected transfer to fail, but it didn't", name, mode)
+			}
+			if val, _ := m.Load("a"); val != 70 {
+				t.Errorf("%s/%d: Expected balance 70 after rollback, but got %d", name, mode, val)
+			}
+
+			// Test read-your-writes and Delete
+			err := m.Txn(func(tx Tx[string, int]) error {
+				tx.Delete("b")
+				if tx.Contain("b") {
+					t.Errorf("%s/%d: Expected key b to be deleted inside the transaction", name, mode)
+				}
+				return nil
+			})
+			if err != nil || m.Contain("b") {
+				t.Errorf("%s/%d: Expected key b to be deleted, but it still exists", name, mode)
+			}
+		}
+	}
+}
+
+func TestTxn_IntSortedSliceMap(t *testing.T) {
+	m := NewThreadSafeIntSortedSliceMap[int, string]().(Transactional[int, string])
+	err := m.Txn(func(tx Tx[int, string]) error {
+		tx.Store(1, "one")
+		tx.Store(2, "two")
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Txn: Expected no error, but got %v", err)
+	}
+	if val, ok := m.Load(2); !ok || val != "two" {
+		t.Errorf("Txn: Expected value 'two', but got '%s'", val)
+	}
+}
+
+func TestTxn_Concurrent(t *testing.T) {
+	for _, mode := range []TxnMode{Pessimistic, Optimistic} {
+		m := NewThreadSafePureMap[string, int](WithTxnMode(mode)).(Transactional[string, int])
+		m.Store("a", 1000)
+		m.Store("b", 1000)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					from, to := "a", "b"
+					if (i+j)%2 == 0 {
+						from, to = to, from
+					}
+					for errors.Is(transfer(m, from, to, 1), ErrTxnConflict) {
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		a, _ := m.Load("a")
+		b, _ := m.Load("b")
+		if a+b != 2000 {
+			t.Errorf("%d: Expected total balance 2000, but got %d", mode, a+b)
+		}
+	}
+}
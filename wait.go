@@ -0,0 +1,98 @@
+package gomap
+
+import (
+	"context"
+	"sync"
+)
+
+// Waitable is implemented by the maps which can wait for a key to be stored.
+// LoadWait returns the value of key as soon as it exists,
+// or the error of ctx if it is done before.
+type Waitable[K comparable, V any] interface {
+	Map[K, V]
+	LoadWait(ctx context.Context, key K) (V, error)
+}
+
+// waitHub keeps the goroutines waiting for keys to be stored,
+// it is guarded by the lock of its map and its zero value is ready to use
+type waitHub[K comparable] struct {
+	waiters map[K]*waitEntry
+}
+
+type waitEntry struct {
+	ch    chan struct{} // closed when the key is stored
+	count int
+}
+
+func (h *waitHub[K]) add(key K) chan struct{} {
+	if h.waiters == nil {
+		h.waiters = make(map[K]*waitEntry)
+	}
+	e, ok := h.waiters[key]
+	if !ok {
+		e = &waitEntry{ch: make(chan struct{})}
+		h.waiters[key] = e
+	}
+	e.count++
+	return e.ch
+}
+
+// remove forgets a waiter which gave up
+func (h *waitHub[K]) remove(key K, ch chan struct{}) {
+	e, ok := h.waiters[key]
+	if !ok || e.ch != ch {
+		// Already woken up
+		return
+	}
+	e.count--
+	if e.count == 0 {
+		delete(h.waiters, key)
+	}
+}
+
+// wake releases the waiters of key
+func (h *waitHub[K]) wake(key K) {
+	if len(h.waiters) == 0 {
+		return
+	}
+	if e, ok := h.waiters[key]; ok {
+		close(e.ch)
+		delete(h.waiters, key)
+	}
+}
+
+// loadWait implements LoadWait for a map guarded by mu,
+// load must be called with mu held
+func loadWait[K comparable, V any](
+	ctx context.Context, mu *sync.RWMutex, h *waitHub[K],
+	load func(key K) (V, bool), key K,
+) (V, error) {
+	mu.RLock()
+	val, ok := load(key)
+	mu.RUnlock()
+	if ok {
+		return val, nil
+	}
+
+	for {
+		mu.Lock()
+		val, ok := load(key)
+		if ok {
+			mu.Unlock()
+			return val, nil
+		}
+		ch := h.add(key)
+		mu.Unlock()
+
+		select {
+		case <-ch:
+			// The key may be deleted again before we get the lock, so check it again
+		case <-ctx.Done():
+			mu.Lock()
+			h.remove(key, ch)
+			mu.Unlock()
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package gomap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLoadWait(t *testing.T) {
+	factories := map[string]func(opts ...Option) Map[int, string]{
+		"ThreadSafePureMap":           NewThreadSafePureMap[int, string],
+		"ThreadSafeSortedSliceMap":    NewThreadSafeSortedSliceMap[int, string],
+		"ThreadSafeIntSortedSliceMap": NewThreadSafeIntSortedSliceMap[int, string],
+	}
+	for name, factory := range factories {
+		m := factory().(Waitable[int, string])
+
+		// Test existing key
+		m.Store(1, "one")
+		val, err := m.LoadWait(context.Background(), 1)
+		if err != nil || val != "one" {
+			t.Errorf("%s: Expected value 'one', but got '%s' and %v", name, val, err)
+		}
+
+		// Test key stored later
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			m.Store(2, "two")
+		}()
+		val, err = m.LoadWait(context.Background(), 2)
+		if err != nil || val != "two" {
+			t.Errorf("%s: Expected value 'two', but got '%s' and %v", name, val, err)
+		}
+
+		// Test cancellation
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err = m.LoadWait(ctx, 3)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: Expected context.DeadlineExceeded, but got %v", name, err)
+		}
+	}
+}
+
+func TestLoadWait_Waiters(t *testing.T) {
+	m := NewThreadSafePureMap[int, string]()
+	results := make(chan string, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			val, _ := m.(Waitable[int, string]).LoadWait(context.Background(), 1)
+			results <- val
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	m.Store(1, "one")
+	for i := 0; i < 10; i++ {
+		if val := <-results; val != "one" {
+			t.Errorf("LoadWait: Expected value 'one', but got '%s'", val)
+		}
+	}
+	if n := len(m.(*threadSafePureMap[int, string]).waits.waiters); n != 0 {
+		t.Errorf("LoadWait: Expected no waiters left, but got %d", n)
+	}
+}
@@ -0,0 +1,246 @@
+package gomap
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/exp/constraints"
+)
+
+// EventType is the kind of change of an Event
+type EventType int
+
+const (
+	// EventPut is sent when a key is stored
+	EventPut EventType = iota
+	// EventDelete is sent when a key is deleted
+	EventDelete
+	// EventClear is sent for every key removed by Clear
+	EventClear
+)
+
+// Event describes a change of a watched key.
+// OldValue is only meaningful if HadOld is true,
+// NewValue is only meaningful for EventPut.
+type Event[K comparable, V any] struct {
+	Type     EventType
+	Key      K
+	OldValue V
+	NewValue V
+	HadOld   bool
+}
+
+// OverflowPolicy decides what happens to a new event
+// when the buffer of a watcher is full
+type OverflowPolicy int
+
+const (
+	// OverflowDrop drops the new event
+	OverflowDrop OverflowPolicy = iota
+	// OverflowBlock blocks the writer until the watcher has room for the event
+	OverflowBlock
+	// OverflowCoalesce merges the new event into a pending event of the same key,
+	// or drops the oldest pending event if there is none
+	OverflowCoalesce
+)
+
+const defaultWatchBuffer = 16
+
+// WithWatchBuffer sets how many events a watcher can hold
+// before its overflow policy applies
+func WithWatchBuffer(size int) Option {
+	return func(o *option) {
+		o.watchBuffer = size
+	}
+}
+
+// WithOverflowPolicy sets the overflow policy of a watcher
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(o *option) {
+		o.overflow = policy
+	}
+}
+
+// Watchable is implemented by the maps which can notify changes of their keys.
+// The returned channel is closed once ctx is done.
+// With OverflowBlock, a consumer of the channel must not write to the map itself.
+type Watchable[K comparable, V any] interface {
+	Map[K, V]
+	Watch(ctx context.Context, key K, opts ...Option) <-chan Event[K, V]
+}
+
+// RangeWatchable is implemented by the ordered maps
+// which can notify changes of the keys in [lo, hi)
+type RangeWatchable[K constraints.Ordered, V any] interface {
+	Watchable[K, V]
+	WatchRange(ctx context.Context, lo, hi K, opts ...Option) <-chan Event[K, V]
+}
+
+// watchHub keeps the watchers of a map, its zero value is ready to use
+type watchHub[K comparable, V any] struct {
+	mu       sync.Mutex
+	watchers map[*watcher[K, V]]struct{}
+	count    atomic.Int32 // lets notify skip the lock when nobody watches
+}
+
+func (h *watchHub[K, V]) watch(ctx context.Context, match func(K) bool, opts []Option) <-chan Event[K, V] {
+	opt := option{watchBuffer: defaultWatchBuffer}
+	for _, o := range opts {
+		o(&opt)
+	}
+	if opt.watchBuffer < 1 {
+		opt.watchBuffer = 1
+	}
+
+	w := &watcher[K, V]{
+		match:  match,
+		buffer: opt.watchBuffer,
+		policy: opt.overflow,
+		out:    make(chan Event[K, V]),
+	}
+	w.cond = sync.NewCond(&w.mu)
+
+	h.mu.Lock()
+	if h.watchers == nil {
+		h.watchers = make(map[*watcher[K, V]]struct{})
+	}
+	h.watchers[w] = struct{}{}
+	h.count.Add(1)
+	h.mu.Unlock()
+
+	go w.pump(ctx)
+	context.AfterFunc(ctx, func() {
+		h.mu.Lock()
+		delete(h.watchers, w)
+		h.count.Add(-1)
+		h.mu.Unlock()
+		w.close()
+	})
+	return w.out
+}
+
+// active reports whether anybody watches, so writers can skip building events
+func (h *watchHub[K, V]) active() bool {
+	return h.count.Load() > 0
+}
+
+// notify sends ev to the watchers of its key,
+// the caller holds the write lock of the map
+func (h *watchHub[K, V]) notify(ev Event[K, V]) {
+	if !h.active() {
+		return
+	}
+
+	h.mu.Lock()
+	matched := make([]*watcher[K, V], 0, len(h.watchers))
+	for w := range h.watchers {
+		if w.match(ev.Key) {
+			matched = append(matched, w)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, w := range matched {
+		w.push(ev)
+	}
+}
+
+type watcher[K comparable, V any] struct {
+	match  func(K) bool
+	buffer int
+	policy OverflowPolicy
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []Event[K, V]
+	closed bool
+
+	out chan Event[K, V]
+}
+
+func (w *watcher[K, V]) push(ev Event[K, V]) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.policy == OverflowBlock {
+		for len(w.queue) >= w.buffer && !w.closed {
+			w.cond.Wait()
+		}
+	}
+	if w.closed {
+		return
+	}
+
+	if len(w.queue) >= w.buffer {
+		switch w.policy {
+		case OverflowDrop:
+			return
+		case OverflowCoalesce:
+			for i := len(w.queue) - 1; i >= 0; i-- {
+				if w.queue[i].Key == ev.Key {
+					w.queue[i] = coalesce(w.queue[i], ev)
+					return
+				}
+			}
+			w.queue = w.queue[1:]
+		}
+	}
+	w.queue = append(w.queue, ev)
+	w.cond.Broadcast()
+}
+
+// coalesce merges two events of the same key into one
+// going from the old state of prev to the new state of next
+func coalesce[K comparable, V any](prev, next Event[K, V]) Event[K, V] {
+	next.OldValue = prev.OldValue
+	next.HadOld = prev.HadOld
+	return next
+}
+
+// pump forwards the queued events to the out channel
+func (w *watcher[K, V]) pump(ctx context.Context) {
+	defer close(w.out)
+
+	for {
+		w.mu.Lock()
+		for len(w.queue) == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if w.closed {
+			w.mu.Unlock()
+			return
+		}
+		ev := w.queue[0]
+		w.queue = w.queue[1:]
+		w.cond.Broadcast()
+		w.mu.Unlock()
+
+		select {
+		case w.out <- ev:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *watcher[K, V]) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	w.queue = nil
+	w.cond.Broadcast()
+}
+
+func keyMatcher[K comparable](key K) func(K) bool {
+	return func(k K) bool {
+		return k == key
+	}
+}
+
+func rangeMatcher[K constraints.Ordered](lo, hi K) func(K) bool {
+	return func(k K) bool {
+		return lo <= k && k < hi
+	}
+}
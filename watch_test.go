@@ -0,0 +1,194 @@
+package gomap
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func receive[K comparable, V any](t *testing.T, ch <-chan Event[K, V]) Event[K, V] {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatalf("Watch: Expected an event, but got none")
+	}
+	return Event[K, V]{}
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := NewThreadSafePureMap[int, string]().(Watchable[int, string])
+	ch := m.Watch(ctx, 1)
+
+	m.Store(2, "two") // not watched
+	m.Store(1, "one")
+	m.Store(1, "uno")
+	m.Delete(1)
+	m.Store(1, "one")
+	m.Clear()
+
+	expected := []Event[int, string]{
+		{Type: EventPut, Key: 1, NewValue: "one"},
+		{Type: EventPut, Key: 1, OldValue: "one", NewValue: "uno", HadOld: true},
+		{Type: EventDelete, Key: 1, OldValue: "uno", HadOld: true},
+		{Type: EventPut, Key: 1, NewValue: "one"},
+		{Type: EventClear, Key: 1, OldValue: "one", HadOld: true},
+	}
+	for _, want := range expected {
+		if got := receive(t, ch); got != want {
+			t.Errorf("Watch: Expected event %+v, but got %+v", want, got)
+		}
+	}
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestWatch_ReadFrom(t *testing.T) {
+	factories := map[string]func(opts ...Option) Map[int, string]{
+		"ThreadSafePureMap":           NewThreadSafePureMap[int, string],
+		"ThreadSafeSortedSliceMap":    NewThreadSafeSortedSliceMap[int, string],
+		"ThreadSafeIntSortedSliceMap": NewThreadSafeIntSortedSliceMap[int, string],
+	}
+	for name, factory := range factories {
+		src := factory()
+		src.Store(1, "uno")
+		src.Store(2, "two")
+		var buf bytes.Buffer
+		if _, err := src.(io.WriterTo).WriteTo(&buf); err != nil {
+			t.Fatalf("%s: Expected no error, but got %v", name, err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		m := factory()
+		m.Store(1, "one")
+		ch := m.(Watchable[int, string]).Watch(ctx, 1)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			m.(io.ReaderFrom).ReadFrom(&buf)
+		}()
+
+		// The waiters of a loaded key are woken up
+		waitCtx, waitCancel := context.WithTimeout(context.Background(), time.Second)
+		val, err := m.(Waitable[int, string]).LoadWait(waitCtx, 2)
+		waitCancel()
+		if err != nil || val != "two" {
+			t.Errorf("%s: Expected value 'two', but got '%s' and %v", name, val, err)
+		}
+
+		expected := []Event[int, string]{
+			{Type: EventClear, Key: 1, OldValue: "one", HadOld: true},
+			{Type: EventPut, Key: 1, NewValue: "uno"},
+		}
+		for _, want := range expected {
+			if got := receive(t, ch); got != want {
+				t.Errorf("%s: Expected event %+v, but got %+v", name, want, got)
+			}
+		}
+		cancel()
+		for range ch {
+		}
+	}
+}
+
+func TestWatchRange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, m := range []RangeWatchable[int, string]{
+		NewThreadSafeSortedSliceMap[int, string]().(RangeWatchable[int, string]),
+		NewThreadSafeIntSortedSliceMap[int, string]().(RangeWatchable[int, string]),
+	} {
+		ch := m.WatchRange(ctx, 10, 20)
+		m.Store(9, "nine")
+		m.Store(20, "twenty")
+		m.Store(10, "ten")
+		m.Store(19, "nineteen")
+
+		if ev := receive(t, ch); ev.Key != 10 {
+			t.Errorf("WatchRange: Expected key 10, but got %d", ev.Key)
+		}
+		if ev := receive(t, ch); ev.Key != 19 {
+			t.Errorf("WatchRange: Expected key 19, but got %d", ev.Key)
+		}
+	}
+}
+
+func TestWatch_Overflow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Drop keeps the first events
+	m := NewThreadSafeSortedSliceMap[int, int]().(RangeWatchable[int, int])
+	ch := m.WatchRange(ctx, 0, 100, WithWatchBuffer(2), WithOverflowPolicy(OverflowDrop))
+	for i := 0; i < 10; i++ {
+		m.Store(i, i)
+	}
+	first := receive(t, ch)
+	if first.Key != 0 {
+		t.Errorf("Drop: Expected key 0, but got %d", first.Key)
+	}
+
+	// Coalesce merges the events of the same key
+	m = NewThreadSafeSortedSliceMap[int, int]().(RangeWatchable[int, int])
+	m.Store(1, 0)
+	ch = m.Watch(ctx, 1, WithWatchBuffer(1), WithOverflowPolicy(OverflowCoalesce))
+	for i := 1; i <= 10; i++ {
+		m.Store(1, i)
+	}
+	// How many events are merged depends on the pump, but the last value always arrives
+	var last Event[int, int]
+	for last.NewValue != 10 {
+		last = receive(t, ch)
+	}
+	if !last.HadOld {
+		t.Errorf("Coalesce: Expected an old value, but got %+v", last)
+	}
+
+	// Without a pump draining it, the queue merges every event
+	w := &watcher[int, int]{match: keyMatcher(1), buffer: 1, policy: OverflowCoalesce}
+	w.cond = sync.NewCond(&w.mu)
+	w.push(Event[int, int]{Type: EventPut, Key: 1, OldValue: 0, NewValue: 1, HadOld: true})
+	for i := 2; i <= 10; i++ {
+		w.push(Event[int, int]{Type: EventPut, Key: 1, OldValue: i - 1, NewValue: i, HadOld: true})
+	}
+	want := Event[int, int]{Type: EventPut, Key: 1, OldValue: 0, NewValue: 10, HadOld: true}
+	if len(w.queue) != 1 || w.queue[0] != want {
+		t.Errorf("Coalesce: Expected the events to be merged into %+v, but got %+v", want, w.queue)
+	}
+
+	// Block delivers every event
+	m = NewThreadSafeSortedSliceMap[int, int]().(RangeWatchable[int, int])
+	ch = m.Watch(ctx, 1, WithWatchBuffer(1), WithOverflowPolicy(OverflowBlock))
+	go func() {
+		for i := 1; i <= 10; i++ {
+			m.Store(1, i)
+		}
+	}()
+	for i := 1; i <= 10; i++ {
+		if ev := receive(t, ch); ev.NewValue != i {
+			t.Errorf("Block: Expected value %d, but got %d", i, ev.NewValue)
+		}
+	}
+}
+
+func TestWatch_Txn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := NewThreadSafePureMap[int, string]()
+	ch := m.(Watchable[int, string]).Watch(ctx, 1)
+	_ = m.(Transactional[int, string]).Txn(func(tx Tx[int, string]) error {
+		tx.Store(1, "one")
+		return nil
+	})
+	if ev := receive(t, ch); ev.NewValue != "one" {
+		t.Errorf("Txn: Expected value 'one', but got '%s'", ev.NewValue)
+	}
+}